@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+	"taskive/storage"
 	"taskive/utils"
 
 	"github.com/joho/godotenv"
@@ -23,6 +26,58 @@ type Config struct {
 	DBName     string
 	JWTSecret  string
 	ServerPort string
+
+	StorageDriver    string
+	StorageLocalPath string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3PathStyle      bool
+
+	AttachmentMaxSize      int64
+	AttachmentProjectQuota int64
+	AttachmentAllowedTypes []string
+	AttachmentSigningKey   string
+
+	TrashRetentionDays int64
+
+	CommentEditWindowMinutes int64
+}
+
+var defaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "text/plain", "application/zip",
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt64(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
 var (
@@ -44,6 +99,24 @@ func LoadConfig() error {
 		DBName:     os.Getenv("DB_NAME"),
 		JWTSecret:  os.Getenv("JWT_SECRET"),
 		ServerPort: os.Getenv("SERVER_PORT"),
+
+		StorageDriver:    getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalPath: getEnv("STORAGE_LOCAL_PATH", "uploads"),
+		S3Endpoint:       os.Getenv("S3_ENDPOINT"),
+		S3Region:         os.Getenv("S3_REGION"),
+		S3Bucket:         os.Getenv("S3_BUCKET"),
+		S3AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:      os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:      os.Getenv("S3_PATH_STYLE") == "true",
+
+		AttachmentMaxSize:      getEnvInt64("ATTACHMENT_MAX_SIZE", 10<<20),
+		AttachmentProjectQuota: getEnvInt64("ATTACHMENT_PROJECT_QUOTA", 500<<20),
+		AttachmentAllowedTypes: getEnvList("ATTACHMENT_ALLOWED_TYPES", defaultAttachmentTypes),
+		AttachmentSigningKey:   getEnv("ATTACHMENT_SIGNING_KEY", os.Getenv("JWT_SECRET")),
+
+		TrashRetentionDays: getEnvInt64("TRASH_RETENTION_DAYS", 30),
+
+		CommentEditWindowMinutes: getEnvInt64("COMMENT_EDIT_WINDOW_MINUTES", 15),
 	}
 
 	return nil
@@ -138,4 +211,31 @@ func InitDB() (*gorm.DB, error) {
 
 	appLogger.LogSuccess("DB", "Connected to database successfully")
 	return db, nil
-} 
\ No newline at end of file
+} 
+
+func InitStorage() (storage.Storage, error) {
+	switch AppConfig.StorageDriver {
+	case "local":
+		store, err := storage.NewLocalStorage(AppConfig.StorageLocalPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare local storage: %w", err)
+		}
+		appLogger.LogInfo("STORAGE", fmt.Sprintf("Storing attachments in '%s'", AppConfig.StorageLocalPath))
+		return store, nil
+	case "s3":
+		store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  AppConfig.S3Endpoint,
+			Region:    AppConfig.S3Region,
+			Bucket:    AppConfig.S3Bucket,
+			AccessKey: AppConfig.S3AccessKey,
+			SecretKey: AppConfig.S3SecretKey,
+			PathStyle: AppConfig.S3PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		appLogger.LogInfo("STORAGE", fmt.Sprintf("Storing attachments in S3 bucket '%s'", AppConfig.S3Bucket))
+		return store, nil
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", AppConfig.StorageDriver)
+}
\ No newline at end of file
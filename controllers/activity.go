@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityController struct {
+	activityService *services.ActivityService
+}
+
+func NewActivityController(activityService *services.ActivityService) *ActivityController {
+	return &ActivityController{
+		activityService: activityService,
+	}
+}
+
+func (c *ActivityController) GetTaskActivity(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	feed, err := c.activityService.GetTaskActivity(uint(taskID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feed)
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultAnalyticsDays = 90
+
+type AnalyticsController struct {
+	analyticsService *services.AnalyticsService
+}
+
+func NewAnalyticsController(analyticsService *services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: analyticsService,
+	}
+}
+
+// parseFlowFilter reads `from`, `to`, `assignee_id` and `priority` from the query string.
+func parseFlowFilter(ctx *gin.Context) (services.FlowFilter, string) {
+	var filter services.FlowFilter
+
+	from, to, err := parseDateRange(ctx, defaultAnalyticsDays)
+	if err != nil {
+		return filter, "invalid date, expected YYYY-MM-DD"
+	}
+	filter.From, filter.To = from, to
+
+	if value := ctx.Query("assignee_id"); value != "" {
+		assigneeID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, "invalid assignee ID"
+		}
+		id := uint(assigneeID)
+		filter.AssigneeID = &id
+	}
+
+	switch priority := models.TaskPriority(ctx.Query("priority")); priority {
+	case "", models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh:
+		filter.Priority = priority
+	default:
+		return filter, "priority must be one of LOW MEDIUM HIGH"
+	}
+
+	return filter, ""
+}
+
+func (c *AnalyticsController) Flow(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	filter, msg := parseFlowFilter(ctx)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	analytics, err := c.analyticsService.Flow(uint(projectID), filter)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+func (c *AnalyticsController) ControlChart(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	filter, msg := parseFlowFilter(ctx)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	points, err := c.analyticsService.ControlChart(uint(projectID), filter)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, points)
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const downloadURLTTL = 5 * time.Minute
+
+type AttachmentController struct {
+	attachmentService *services.AttachmentService
+	maxSize           int64
+}
+
+func NewAttachmentController(attachmentService *services.AttachmentService, maxSize int64) *AttachmentController {
+	return &AttachmentController{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+	}
+}
+
+func attachmentErrorStatus(err error) int {
+	switch err {
+	case models.ErrAttachmentNotFound, models.ErrThumbnailNotReady, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrAttachmentTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case models.ErrAttachmentType:
+		return http.StatusUnsupportedMediaType
+	case models.ErrCoverNotImage:
+		return http.StatusBadRequest
+	case models.ErrAttachmentQuota:
+		return http.StatusInsufficientStorage
+	case models.ErrInvalidDownloadLink, models.ErrForbidden:
+		return http.StatusForbidden
+	case models.ErrProjectArchived:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// upload reads the multipart "file" field and stores it as an attachment.
+func (c *AttachmentController) upload(ctx *gin.Context, input services.UploadInput) {
+	if c.maxSize > 0 {
+		// Leave room for the multipart envelope around the file itself.
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxSize+1<<20)
+	}
+
+	header, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": models.ErrAttachmentTooLarge.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	input.UploaderID = ctx.GetUint("user_id")
+	input.FileName = header.Filename
+	input.Size = header.Size
+	input.Body = file
+
+	attachment, err := c.attachmentService.Upload(input)
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, attachment)
+}
+
+func (c *AttachmentController) UploadToTask(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	c.upload(ctx, services.UploadInput{TaskID: uint(taskID)})
+}
+
+func (c *AttachmentController) UploadToComment(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	id := uint(commentID)
+	c.upload(ctx, services.UploadInput{CommentID: &id})
+}
+
+func (c *AttachmentController) GetTaskAttachments(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	attachments, err := c.attachmentService.GetTaskAttachments(uint(taskID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+func (c *AttachmentController) GetCommentAttachments(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	attachments, err := c.attachmentService.GetCommentAttachments(uint(commentID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+func (c *AttachmentController) GetByID(ctx *gin.Context) {
+	attachmentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	attachment, err := c.attachmentService.GetByID(uint(attachmentID))
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachment)
+}
+
+func (c *AttachmentController) Delete(ctx *gin.Context) {
+	attachmentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	if err := c.attachmentService.Delete(uint(attachmentID)); err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *AttachmentController) GetDownloadURL(ctx *gin.Context) {
+	attachmentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	signed, err := c.attachmentService.SignDownload(uint(attachmentID), userID, downloadURLTTL)
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, signed)
+}
+
+// Download serves a file through a signed URL and needs no Authorization header.
+func (c *AttachmentController) Download(ctx *gin.Context) {
+	attachmentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	attachment, body, err := c.attachmentService.OpenSigned(uint(attachmentID), false, ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, nil)
+}
+
+// DownloadThumbnail serves an image preview through a signed URL.
+func (c *AttachmentController) DownloadThumbnail(ctx *gin.Context) {
+	attachmentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	attachment, body, err := c.attachmentService.OpenSigned(uint(attachmentID), true, ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	contentType := "image/png"
+	if attachment.ContentType == "image/jpeg" {
+		contentType = "image/jpeg"
+	}
+	ctx.Header("Content-Disposition", "inline")
+	ctx.Header("Cache-Control", "private, max-age=3600")
+	ctx.DataFromReader(http.StatusOK, -1, contentType, body, nil)
+}
+
+type setCoverInput struct {
+	AttachmentID *uint `json:"attachment_id"`
+}
+
+// SetTaskCover sets or clears (attachment_id: null) the task's cover image.
+func (c *AttachmentController) SetTaskCover(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input setCoverInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := c.attachmentService.SetTaskCover(uint(taskID), input.AttachmentID)
+	if err != nil {
+		ctx.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService *services.AuditService
+}
+
+func NewAuditController(auditService *services.AuditService) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+	}
+}
+
+// auditMeta collects the request details recorded with audit entries.
+func auditMeta(ctx *gin.Context) services.AuditMeta {
+	return services.AuditMeta{
+		ActorID:   ctx.GetUint("user_id"),
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+func parseOptionalID(ctx *gin.Context, name string) (*uint, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	result := uint(id)
+	return &result, true
+}
+
+// parseOptionalTime accepts either a date (YYYY-MM-DD) or an RFC 3339 timestamp.
+func parseOptionalTime(ctx *gin.Context, name string) (time.Time, bool) {
+	return parseTimeValue(ctx.Query(name))
+}
+
+func parseTimeValue(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, err == nil
+}
+
+func parseAuditFilter(ctx *gin.Context) (services.AuditFilter, string) {
+	filter := services.AuditFilter{
+		Action:     ctx.Query("action"),
+		TargetType: ctx.Query("target_type"),
+	}
+
+	var ok bool
+	if filter.ActorID, ok = parseOptionalID(ctx, "actor_id"); !ok {
+		return filter, "invalid actor ID"
+	}
+	if filter.ProjectID, ok = parseOptionalID(ctx, "project_id"); !ok {
+		return filter, "invalid project ID"
+	}
+	if filter.TargetID, ok = parseOptionalID(ctx, "target_id"); !ok {
+		return filter, "invalid target ID"
+	}
+	if filter.From, ok = parseOptionalTime(ctx, "from"); !ok {
+		return filter, "invalid from, expected YYYY-MM-DD or RFC 3339"
+	}
+	if filter.To, ok = parseOptionalTime(ctx, "to"); !ok {
+		return filter, "invalid to, expected YYYY-MM-DD or RFC 3339"
+	}
+
+	return filter, ""
+}
+
+func (c *AuditController) List(ctx *gin.Context) {
+	filter, msg := parseAuditFilter(ctx)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "50"))
+
+	result, err := c.auditService.List(filter, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *AuditController) Export(ctx *gin.Context) {
+	filter, msg := parseAuditFilter(ctx)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "jsonl")
+	var write func([]models.AuditLog) error
+	var csvWriter *csv.Writer
+
+	switch format {
+	case "jsonl":
+		ctx.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(ctx.Writer)
+		write = func(logs []models.AuditLog) error {
+			for _, log := range logs {
+				if err := encoder.Encode(log); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case "csv":
+		ctx.Header("Content-Type", "text/csv")
+		writer := csv.NewWriter(ctx.Writer)
+		csvWriter = writer
+		header := []string{"id", "created_at", "actor_id", "ip", "user_agent", "action", "target_type", "target_id", "project_id", "before", "after"}
+		if err := writer.Write(header); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		write = func(logs []models.AuditLog) error {
+			for _, log := range logs {
+				record := []string{
+					strconv.FormatUint(uint64(log.ID), 10),
+					log.CreatedAt.UTC().Format(time.RFC3339),
+					optionalID(log.ActorID),
+					log.IP,
+					log.UserAgent,
+					log.Action,
+					log.TargetType,
+					strconv.FormatUint(uint64(log.TargetID), 10),
+					optionalID(log.ProjectID),
+					string(log.Before),
+					string(log.After),
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=audit-log."+format)
+	ctx.Status(http.StatusOK)
+	if err := c.auditService.Export(filter, write); err != nil {
+		// Headers are already sent, so the error can only be logged.
+		ctx.Error(err)
+	}
+	if csvWriter != nil {
+		// Writes the header row even when no entries matched.
+		csvWriter.Flush()
+	}
+}
+
+func optionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
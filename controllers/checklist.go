@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type ChecklistController struct {
+	checklistService *services.ChecklistService
+	validate         *validator.Validate
+}
+
+func NewChecklistController(checklistService *services.ChecklistService) *ChecklistController {
+	return &ChecklistController{
+		checklistService: checklistService,
+		validate:         validator.New(),
+	}
+}
+
+func checklistErrorStatus(err error) int {
+	if errors.Is(err, models.ErrInvalidPatch) {
+		return http.StatusBadRequest
+	}
+	switch err {
+	case models.ErrChecklistItemNotFound, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrChecklistOrder:
+		return http.StatusBadRequest
+	case models.ErrProjectArchived:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *ChecklistController) GetTaskChecklist(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	items, err := c.checklistService.GetTaskChecklist(uint(taskID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+func (c *ChecklistController) Create(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input services.CreateChecklistItemInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := c.checklistService.Create(uint(taskID), input)
+	if err != nil {
+		ctx.JSON(checklistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, item)
+}
+
+func (c *ChecklistController) Reorder(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input services.ReorderChecklistInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := c.checklistService.Reorder(uint(taskID), input.ItemIDs)
+	if err != nil {
+		ctx.JSON(checklistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+func (c *ChecklistController) Update(ctx *gin.Context) {
+	itemID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid checklist item ID"})
+		return
+	}
+
+	var input services.UpdateChecklistItemInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := c.checklistService.Update(uint(itemID), input)
+	if err != nil {
+		ctx.JSON(checklistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func (c *ChecklistController) Delete(ctx *gin.Context) {
+	itemID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid checklist item ID"})
+		return
+	}
+
+	if err := c.checklistService.Delete(uint(itemID)); err != nil {
+		ctx.JSON(checklistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"taskive/models"
 	"taskive/services"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,16 @@ func NewCommentController(commentService *services.CommentService) *CommentContr
 	}
 }
 
+func commentErrorStatus(err error) int {
+	switch err {
+	case models.ErrCommentNotAuthor, models.ErrCommentEditWindow, models.ErrForbidden:
+		return http.StatusForbidden
+	case models.ErrCommentParent, models.ErrCommentDepth:
+		return http.StatusBadRequest
+	}
+	return projectErrorStatus(err)
+}
+
 func (c *CommentController) Create(ctx *gin.Context) {
 	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -42,7 +53,7 @@ func (c *CommentController) Create(ctx *gin.Context) {
 	userID := ctx.GetUint("user_id")
 	comment, err := c.commentService.Create(uint(taskID), userID, input)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -65,6 +76,110 @@ func (c *CommentController) GetTaskComments(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, comments)
 }
 
+// parseThreadDepth reads the depth query parameter; 0, the default,
+// returns every level.
+func parseThreadDepth(ctx *gin.Context) (int, bool) {
+	value := ctx.Query("depth")
+	if value == "" {
+		return 0, true
+	}
+	depth, err := strconv.Atoi(value)
+	return depth, err == nil && depth >= 0
+}
+
+// GetTaskThreads returns the task's comments as threads. Replies below
+// ?depth=N are collapsed into their parent's reply_count.
+func (c *CommentController) GetTaskThreads(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+	depth, ok := parseThreadDepth(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	threads, err := c.commentService.GetTaskThreads(uint(taskID), userID, depth)
+	if err != nil {
+		ctx.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, threads)
+}
+
+// GetReplies expands the replies of a collapsed comment.
+func (c *CommentController) GetReplies(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+	depth, ok := parseThreadDepth(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	replies, err := c.commentService.GetReplies(uint(commentID), userID, depth)
+	if err != nil {
+		ctx.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, replies)
+}
+
+// Update edits the text of the caller's own comment.
+func (c *CommentController) Update(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var input services.UpdateCommentInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	comment, err := c.commentService.Update(uint(commentID), userID, input)
+	if err != nil {
+		ctx.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+func (c *CommentController) GetRevisions(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	revisions, err := c.commentService.GetRevisions(uint(commentID), userID)
+	if err != nil {
+		ctx.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
 func (c *CommentController) Delete(ctx *gin.Context) {
 	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -73,7 +188,7 @@ func (c *CommentController) Delete(ctx *gin.Context) {
 	}
 
 	if err := c.commentService.Delete(uint(commentID)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
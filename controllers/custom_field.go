@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type CustomFieldController struct {
+	customFieldService *services.CustomFieldService
+	validate           *validator.Validate
+}
+
+func NewCustomFieldController(customFieldService *services.CustomFieldService) *CustomFieldController {
+	return &CustomFieldController{
+		customFieldService: customFieldService,
+		validate:           validator.New(),
+	}
+}
+
+// customFieldErrorStatus maps custom field errors, which may carry the
+// offending field name, to a status code. It returns 0 for other errors.
+func customFieldErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrCustomFieldNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrCustomFieldExists), errors.Is(err, models.ErrProjectArchived):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrCustomFieldType), errors.Is(err, models.ErrCustomFieldOptions),
+		errors.Is(err, models.ErrCustomFieldValue), errors.Is(err, models.ErrCustomFieldRequired),
+		errors.Is(err, models.ErrCustomFieldSort):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+// bracketParams collects query parameters of the form name[key]=value.
+func bracketParams(values url.Values, name string) map[string]string {
+	result := map[string]string{}
+	for param, vals := range values {
+		if len(vals) == 0 || !strings.HasPrefix(param, name+"[") || !strings.HasSuffix(param, "]") {
+			continue
+		}
+		result[param[len(name)+1:len(param)-1]] = vals[0]
+	}
+	return result
+}
+
+// parseCustomFieldConditions reads custom field filters from the query
+// string: cf[<id>]=value, cf_gte[<id>]=value and cf_lte[<id>]=value.
+func parseCustomFieldConditions(values url.Values) ([]services.CustomFieldCondition, bool) {
+	var conditions []services.CustomFieldCondition
+	for param, op := range map[string]string{"cf": "eq", "cf_gte": "gte", "cf_lte": "lte"} {
+		for key, value := range bracketParams(values, param) {
+			id, err := strconv.ParseUint(key, 10, 32)
+			if err != nil {
+				return nil, false
+			}
+			conditions = append(conditions, services.CustomFieldCondition{
+				FieldID: uint(id),
+				Op:      op,
+				Value:   value,
+			})
+		}
+	}
+	return conditions, true
+}
+
+func (c *CustomFieldController) GetProjectFields(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	fields, err := c.customFieldService.GetProjectFields(uint(projectID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fields)
+}
+
+func (c *CustomFieldController) Create(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.CreateCustomFieldInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := c.customFieldService.Create(uint(projectID), input)
+	if err != nil {
+		ctx.JSON(errorStatus(customFieldErrorStatus(err)), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, field)
+}
+
+func (c *CustomFieldController) Update(ctx *gin.Context) {
+	fieldID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid custom field ID"})
+		return
+	}
+
+	var input services.UpdateCustomFieldInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := c.customFieldService.Update(uint(fieldID), input)
+	if err != nil {
+		ctx.JSON(errorStatus(customFieldErrorStatus(err)), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, field)
+}
+
+func (c *CustomFieldController) Delete(ctx *gin.Context) {
+	fieldID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid custom field ID"})
+		return
+	}
+
+	if err := c.customFieldService.Delete(uint(fieldID)); err != nil {
+		ctx.JSON(errorStatus(customFieldErrorStatus(err)), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *CustomFieldController) SetTaskValues(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input services.CustomFieldValues
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	values, err := c.customFieldService.SetTaskValues(uint(taskID), userID, input)
+	if err != nil {
+		ctx.JSON(errorStatus(customFieldErrorStatus(err)), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, values)
+}
+
+// errorStatus falls back to 500 when no specific status was found.
+func errorStatus(status int) int {
+	if status == 0 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
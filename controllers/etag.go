@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats the version of a task or project as an entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the If-Match header. It returns 0 when the header is
+// absent or "*", so the write is unconditional, and false when the header
+// does not name a version this API could have issued.
+func ifMatchVersion(ctx *gin.Context) (int, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// respondVersioned writes body with its ETag, or 304 Not Modified when the
+// client's If-None-Match already names that version.
+func respondVersioned(ctx *gin.Context, status, version int, body interface{}) {
+	tag := etag(version)
+	ctx.Header("ETag", tag)
+	if ctx.Request.Method == http.MethodGet {
+		for _, candidate := range strings.Split(ctx.GetHeader("If-None-Match"), ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == tag || candidate == "*" {
+				ctx.Status(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	ctx.JSON(status, body)
+}
@@ -46,8 +46,8 @@ func (c *InvitationController) RespondToInvitation(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.invitationService.RespondToInvitation(userID, uint(projectID), input.Accept); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.invitationService.RespondToInvitation(userID, uint(projectID), input.Accept, auditMeta(ctx)); err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
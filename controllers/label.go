@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type LabelController struct {
+	labelService *services.LabelService
+	validate     *validator.Validate
+}
+
+func NewLabelController(labelService *services.LabelService) *LabelController {
+	return &LabelController{
+		labelService: labelService,
+		validate:     validator.New(),
+	}
+}
+
+func labelErrorStatus(err error) int {
+	switch err {
+	case models.ErrLabelNotFound, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrLabelExists:
+		return http.StatusConflict
+	case models.ErrLabelProject, models.ErrLabelMerge, models.ErrLabelName:
+		return http.StatusBadRequest
+	case models.ErrProjectArchived:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// parseIDList parses a comma separated list of IDs such as "1,4,7".
+func parseIDList(value string) ([]uint, bool) {
+	if value == "" {
+		return nil, true
+	}
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, true
+}
+
+func (c *LabelController) GetProjectLabels(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	labels, err := c.labelService.GetProjectLabels(uint(projectID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, labels)
+}
+
+func (c *LabelController) Create(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.CreateLabelInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := c.labelService.Create(uint(projectID), input)
+	if err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, label)
+}
+
+func (c *LabelController) Merge(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.MergeLabelsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := c.labelService.Merge(uint(projectID), input)
+	if err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, label)
+}
+
+func (c *LabelController) Update(ctx *gin.Context) {
+	labelID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return
+	}
+
+	var input services.UpdateLabelInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := c.labelService.Update(uint(labelID), input)
+	if err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, label)
+}
+
+func (c *LabelController) Delete(ctx *gin.Context) {
+	labelID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return
+	}
+
+	if err := c.labelService.Delete(uint(labelID)); err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *LabelController) SetTaskLabels(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input services.SetTaskLabelsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels, err := c.labelService.SetTaskLabels(uint(taskID), input.LabelIDs)
+	if err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, labels)
+}
+
+func (c *LabelController) AddTaskLabel(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+	labelID, err := strconv.ParseUint(ctx.Param("label_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return
+	}
+
+	if err := c.labelService.AddTaskLabel(uint(taskID), uint(labelID)); err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *LabelController) RemoveTaskLabel(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+	labelID, err := strconv.ParseUint(ctx.Param("label_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid label ID"})
+		return
+	}
+
+	if err := c.labelService.RemoveTaskLabel(uint(taskID), uint(labelID)); err != nil {
+		ctx.JSON(labelErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionController struct {
+	mentionService *services.MentionService
+}
+
+func NewMentionController(mentionService *services.MentionService) *MentionController {
+	return &MentionController{
+		mentionService: mentionService,
+	}
+}
+
+func (c *MentionController) GetMyMentions(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	unreadOnly := ctx.Query("unread") == "true"
+
+	userID := ctx.GetUint("user_id")
+	mentions, err := c.mentionService.GetUserMentions(userID, unreadOnly, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mentions)
+}
+
+func (c *MentionController) MarkRead(ctx *gin.Context) {
+	mentionID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid mention ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	if err := c.mentionService.MarkRead(uint(mentionID), userID); err != nil {
+		status := http.StatusInternalServerError
+		if err == models.ErrMentionNotFound {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *MentionController) MarkAllRead(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	if err := c.mentionService.MarkAllRead(userID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 )
 
 type ProjectController struct {
@@ -38,7 +40,7 @@ func (c *ProjectController) Create(ctx *gin.Context) {
 	userID := ctx.GetUint("user_id")
 	project, err := c.projectService.Create(userID, input)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -58,13 +60,67 @@ func (c *ProjectController) Update(ctx *gin.Context) {
 		return
 	}
 
-	project, err := c.projectService.Update(uint(projectID), input)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		c.respondConflict(ctx, uint(projectID))
+		return
+	}
+
+	project, err := c.projectService.Update(uint(projectID), input, version)
+	if err == models.ErrVersionConflict {
+		c.respondConflict(ctx, uint(projectID))
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, project)
+	respondVersioned(ctx, http.StatusOK, project.Version, project)
+}
+
+// Patch applies an RFC 7396 merge patch: absent fields stay untouched and
+// null clears a field. If-Match works as for Update.
+func (c *ProjectController) Patch(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var patch services.MergePatch
+	if err := ctx.ShouldBindJSON(&patch); err != nil || patch == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "patch must be a JSON object"})
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		c.respondConflict(ctx, uint(projectID))
+		return
+	}
+
+	project, err := c.projectService.Patch(uint(projectID), patch, version)
+	if err == models.ErrVersionConflict {
+		c.respondConflict(ctx, uint(projectID))
+		return
+	}
+	if err != nil {
+		ctx.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	respondVersioned(ctx, http.StatusOK, project.Version, project)
+}
+
+// respondConflict answers a failed If-Match with 412 and the current project.
+func (c *ProjectController) respondConflict(ctx *gin.Context, projectID uint) {
+	project, err := c.projectService.GetByID(projectID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+	respondVersioned(ctx, http.StatusPreconditionFailed, project.Version, project)
 }
 
 func (c *ProjectController) Delete(ctx *gin.Context) {
@@ -74,7 +130,7 @@ func (c *ProjectController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.projectService.Delete(uint(projectID)); err != nil {
+	if err := c.projectService.Delete(uint(projectID), auditMeta(ctx)); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -82,9 +138,12 @@ func (c *ProjectController) Delete(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// GetUserProjects lists the user's projects; pass include_archived=true to
+// list archived ones as well.
 func (c *ProjectController) GetUserProjects(ctx *gin.Context) {
 	userID := ctx.GetUint("user_id")
-	projects, err := c.projectService.GetUserProjects(userID)
+	includeArchived := ctx.Query("include_archived") == "true"
+	projects, err := c.projectService.GetUserProjects(userID, includeArchived)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -106,7 +165,7 @@ func (c *ProjectController) GetByID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, project)
+	respondVersioned(ctx, http.StatusOK, project.Version, project)
 }
 
 func (c *ProjectController) AddMember(ctx *gin.Context) {
@@ -131,14 +190,125 @@ func (c *ProjectController) AddMember(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.projectService.AddMember(uint(projectID), input.UserID, input.Role); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.projectService.AddMember(uint(projectID), input.UserID, input.Role, auditMeta(ctx)); err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.Status(http.StatusCreated)
 }
 
+func (c *ProjectController) UpdateMemberRole(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("user_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var input struct {
+		Role models.MemberRole `json:"role" validate:"required,oneof=OWNER EDITOR VIEWER"`
+	}
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := c.projectService.UpdateMemberRole(uint(projectID), uint(userID), input.Role, auditMeta(ctx))
+	if err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (c *ProjectController) RemoveMember(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(ctx.Param("user_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := c.projectService.RemoveMember(uint(projectID), uint(userID), auditMeta(ctx)); err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func projectErrorStatus(err error) int {
+	if errors.Is(err, models.ErrInvalidPatch) {
+		return http.StatusBadRequest
+	}
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrProjectArchived, models.ErrProjectKeyExists:
+		return http.StatusConflict
+	case models.ErrInvalidProjectKey:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *ProjectController) Archive(ctx *gin.Context) {
+	c.setArchived(ctx, true)
+}
+
+func (c *ProjectController) Unarchive(ctx *gin.Context) {
+	c.setArchived(ctx, false)
+}
+
+func (c *ProjectController) setArchived(ctx *gin.Context, archived bool) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var project *models.Project
+	if archived {
+		project, err = c.projectService.Archive(uint(projectID), auditMeta(ctx))
+	} else {
+		project, err = c.projectService.Unarchive(uint(projectID), auditMeta(ctx))
+	}
+	if err != nil {
+		ctx.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, project)
+}
+
+func memberErrorStatus(err error) int {
+	switch err {
+	case models.ErrMemberNotFound:
+		return http.StatusNotFound
+	case models.ErrLastOwner, models.ErrProjectArchived:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 func (c *ProjectController) GetUserInvitations(ctx *gin.Context) {
 	userID := ctx.GetUint("user_id")
 	fmt.Printf("Getting invitations for user ID: %d\n", userID) // Debug log
@@ -161,8 +331,8 @@ func (c *ProjectController) AcceptInvitation(ctx *gin.Context) {
 	}
 
 	userID := ctx.GetUint("user_id")
-	if err := c.projectService.AcceptInvitation(uint(projectID), userID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.projectService.AcceptInvitation(uint(projectID), userID, auditMeta(ctx)); err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -177,8 +347,8 @@ func (c *ProjectController) RejectInvitation(ctx *gin.Context) {
 	}
 
 	userID := ctx.GetUint("user_id")
-	if err := c.projectService.RejectInvitation(uint(projectID), userID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.projectService.RejectInvitation(uint(projectID), userID, auditMeta(ctx)); err != nil {
+		ctx.JSON(memberErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultReportDays = 30
+
+type ReportController struct {
+	reportService *services.ReportService
+}
+
+func NewReportController(reportService *services.ReportService) *ReportController {
+	return &ReportController{
+		reportService: reportService,
+	}
+}
+
+func reportErrorStatus(err error) int {
+	switch err {
+	case models.ErrInvalidReportRange:
+		return http.StatusBadRequest
+	case models.ErrSprintNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// parseDateRange reads the optional `from` and `to` query parameters
+// (YYYY-MM-DD). Without them the range covers the last defaultDays days.
+func parseDateRange(ctx *gin.Context, defaultDays int) (time.Time, time.Time, error) {
+	to := time.Now()
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultDays - 1))
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+
+	return from, to, nil
+}
+
+func (c *ReportController) SprintBurndown(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	series, err := c.reportService.SprintBurndown(uint(sprintID))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+func (c *ReportController) SprintBurnup(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	series, err := c.reportService.SprintBurnup(uint(sprintID))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+func (c *ReportController) ProjectBurndown(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	from, to, err := parseDateRange(ctx, defaultReportDays)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	series, err := c.reportService.ProjectBurndown(uint(projectID), from, to)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+func (c *ReportController) ProjectBurnup(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	from, to, err := parseDateRange(ctx, defaultReportDays)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	series, err := c.reportService.ProjectBurnup(uint(projectID), from, to)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+func (c *ReportController) CumulativeFlow(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	from, to, err := parseDateRange(ctx, defaultReportDays)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	series, err := c.reportService.CumulativeFlow(uint(projectID), from, to)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, series)
+}
+
+func (c *ReportController) Velocity(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("sprints", "5"))
+	if err != nil || limit < 1 || limit > 50 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sprints must be between 1 and 50"})
+		return
+	}
+
+	report, err := c.reportService.Velocity(uint(projectID), limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+	"taskive/taskql"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type SavedViewController struct {
+	savedViewService *services.SavedViewService
+	taskService      *services.TaskService
+	validate         *validator.Validate
+}
+
+func NewSavedViewController(savedViewService *services.SavedViewService, taskService *services.TaskService) *SavedViewController {
+	return &SavedViewController{
+		savedViewService: savedViewService,
+		taskService:      taskService,
+		validate:         validator.New(),
+	}
+}
+
+func savedViewErrorStatus(err error) int {
+	switch err {
+	case models.ErrSavedViewNotFound, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// checkViewSpec makes sure a view's filter and query can be run before it
+// is saved.
+func checkViewSpec(ctx *gin.Context, filter, query string) bool {
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter: " + err.Error()})
+		return false
+	}
+	if _, msg := parseTaskFilter(values, 0); msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter: " + msg})
+		return false
+	}
+	if _, err := taskql.Parse(query, time.Now()); err != nil {
+		respondTaskQueryError(ctx, err)
+		return false
+	}
+	return true
+}
+
+func (c *SavedViewController) GetProjectViews(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	views, err := c.savedViewService.GetProjectViews(uint(projectID), userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, views)
+}
+
+func (c *SavedViewController) Create(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.CreateSavedViewInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkViewSpec(ctx, input.Filter, input.Query) {
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	view, err := c.savedViewService.Create(uint(projectID), userID, input)
+	if err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, view)
+}
+
+func (c *SavedViewController) GetByID(ctx *gin.Context) {
+	viewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid view ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	view, err := c.savedViewService.GetByID(uint(viewID), userID)
+	if err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+func (c *SavedViewController) Update(ctx *gin.Context) {
+	viewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid view ID"})
+		return
+	}
+
+	var input services.UpdateSavedViewInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filter, query string
+	if input.Filter != nil {
+		filter = *input.Filter
+	}
+	if input.Query != nil {
+		query = *input.Query
+	}
+	if !checkViewSpec(ctx, filter, query) {
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	view, err := c.savedViewService.Update(uint(viewID), userID, input)
+	if err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+func (c *SavedViewController) Delete(ctx *gin.Context) {
+	viewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid view ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	if err := c.savedViewService.Delete(uint(viewID), userID); err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *SavedViewController) GetDefault(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	view, err := c.savedViewService.GetDefault(uint(projectID), userID)
+	if err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+// SetDefault sets ({"view_id": 3}) or clears ({"view_id": null}) the
+// caller's default view of the project.
+func (c *SavedViewController) SetDefault(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input struct {
+		ViewID *uint `json:"view_id"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	if err := c.savedViewService.SetDefault(uint(projectID), userID, input.ViewID); err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Run executes the view for the caller, so "me" means the caller. cursor
+// and limit are taken from the request.
+func (c *SavedViewController) Run(ctx *gin.Context) {
+	viewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid view ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	view, err := c.savedViewService.GetByID(uint(viewID), userID)
+	if err != nil {
+		ctx.JSON(savedViewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	values, err := url.ParseQuery(view.Filter)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "saved filter is invalid: " + err.Error()})
+		return
+	}
+	if view.Query != "" {
+		values.Set("q", view.Query)
+	}
+	if view.Sort != "" {
+		values.Set("sort", view.Sort)
+	}
+	values.Set("cursor", ctx.Query("cursor"))
+	values.Set("limit", ctx.Query("limit"))
+
+	filter, msg := parseTaskFilter(values, userID)
+	if msg != "" {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "saved filter is invalid: " + msg})
+		return
+	}
+
+	page, err := c.taskService.GetProjectTasks(view.ProjectID, filter)
+	if err != nil {
+		respondTaskQueryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"view": view, "results": page})
+}
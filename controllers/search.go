@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	searchService *services.SearchService
+}
+
+func NewSearchController(searchService *services.SearchService) *SearchController {
+	return &SearchController{
+		searchService: searchService,
+	}
+}
+
+// Search runs a full-text search, e.g. /api/search?q="invoice export"&type=task,comment.
+func (c *SearchController) Search(ctx *gin.Context) {
+	var params services.SearchParams
+	params.Types = parseList(ctx.Query("type"))
+
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		params.Limit = limit
+	}
+	if value := ctx.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		params.Offset = offset
+	}
+
+	userID := ctx.GetUint("user_id")
+	page, err := c.searchService.Search(userID, ctx.Query("q"), params)
+	if err != nil {
+		switch err {
+		case models.ErrSearchQuery, models.ErrSearchType:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
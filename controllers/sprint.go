@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SprintController struct {
+	sprintService *services.SprintService
+	validate      *validator.Validate
+}
+
+func NewSprintController(sprintService *services.SprintService) *SprintController {
+	return &SprintController{
+		sprintService: sprintService,
+		validate:      validator.New(),
+	}
+}
+
+func sprintErrorStatus(err error) int {
+	switch err {
+	case models.ErrSprintNotFound:
+		return http.StatusNotFound
+	case models.ErrSprintAlreadyActive, models.ErrSprintNotPlanned, models.ErrSprintNotActive, models.ErrSprintClosed:
+		return http.StatusConflict
+	case models.ErrSprintProject, models.ErrSprintRollover:
+		return http.StatusBadRequest
+	}
+	return projectErrorStatus(err)
+}
+
+func (c *SprintController) Create(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.CreateSprintInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sprint, err := c.sprintService.Create(uint(projectID), input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, sprint)
+}
+
+func (c *SprintController) GetProjectSprints(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	sprints, err := c.sprintService.GetProjectSprints(uint(projectID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sprints)
+}
+
+func (c *SprintController) GetBacklog(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	tasks, err := c.sprintService.GetBacklog(uint(projectID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+func (c *SprintController) GetByID(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	sprint, err := c.sprintService.GetByID(uint(sprintID))
+	if err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sprint)
+}
+
+func (c *SprintController) Update(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	var input services.UpdateSprintInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sprint, err := c.sprintService.Update(uint(sprintID), input)
+	if err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sprint)
+}
+
+func (c *SprintController) Delete(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	if err := c.sprintService.Delete(uint(sprintID)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *SprintController) GetSprintTasks(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	tasks, err := c.sprintService.GetSprintTasks(uint(sprintID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+func (c *SprintController) AssignTasks(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	var input services.AssignSprintTasksInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.sprintService.AssignTasks(uint(sprintID), input.TaskIDs); err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *SprintController) UnassignTasks(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	var input services.AssignSprintTasksInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.sprintService.UnassignTasks(uint(sprintID), input.TaskIDs); err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *SprintController) Start(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	sprint, err := c.sprintService.Start(uint(sprintID))
+	if err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sprint)
+}
+
+func (c *SprintController) Close(ctx *gin.Context) {
+	sprintID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sprint ID"})
+		return
+	}
+
+	// The body is optional: without it unfinished tasks return to the backlog.
+	var input services.CloseSprintInput
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&input); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := c.sprintService.Close(uint(sprintID), input)
+	if err != nil {
+		ctx.JSON(sprintErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
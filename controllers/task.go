@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"taskive/models"
 	"taskive/services"
+	"taskive/taskql"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -22,6 +27,141 @@ func NewTaskController(taskService *services.TaskService) *TaskController {
 	}
 }
 
+func taskErrorStatus(err error) int {
+	if status := customFieldErrorStatus(err); status != 0 {
+		return status
+	}
+	if errors.Is(err, models.ErrInvalidPatch) {
+		return http.StatusBadRequest
+	}
+	switch err {
+	case models.ErrInvalidSort, models.ErrInvalidCursor, models.ErrMoveSameProject, models.ErrAssigneeNotMember,
+		models.ErrBulkTarget, models.ErrBulkNoChanges, models.ErrBulkTooMany, models.ErrLabelProject:
+		return http.StatusBadRequest
+	case models.ErrForbidden:
+		return http.StatusForbidden
+	case models.ErrLabelNotFound:
+		return http.StatusNotFound
+	case models.ErrAttachmentQuota:
+		return http.StatusInsufficientStorage
+	}
+	return sprintErrorStatus(err)
+}
+
+// respondTaskQueryError reports listing errors, including the position of
+// query language syntax errors.
+func respondTaskQueryError(ctx *gin.Context, err error) {
+	var syntaxErr *taskql.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": syntaxErr.Error(), "position": syntaxErr.Pos})
+		return
+	}
+	ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+}
+
+// parseList splits a comma separated query value, skipping blanks.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTaskFilter reads task list filters, sorting and pagination from the
+// query string. The assignee list accepts "me" and "unassigned" besides
+// user IDs.
+func parseTaskFilter(values url.Values, userID uint) (services.TaskFilter, string) {
+	var filter services.TaskFilter
+
+	for _, status := range parseList(values.Get("status")) {
+		switch models.TaskStatus(status) {
+		case models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusDone:
+			filter.Statuses = append(filter.Statuses, models.TaskStatus(status))
+		default:
+			return filter, "invalid status " + status
+		}
+	}
+	for _, priority := range parseList(values.Get("priority")) {
+		switch models.TaskPriority(priority) {
+		case models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh:
+			filter.Priorities = append(filter.Priorities, models.TaskPriority(priority))
+		default:
+			return filter, "invalid priority " + priority
+		}
+	}
+	for _, assignee := range parseList(values.Get("assignee")) {
+		switch assignee {
+		case "me":
+			filter.AssigneeIDs = append(filter.AssigneeIDs, userID)
+		case "unassigned":
+			filter.Unassigned = true
+		default:
+			id, err := strconv.ParseUint(assignee, 10, 32)
+			if err != nil {
+				return filter, "invalid assignee " + assignee
+			}
+			filter.AssigneeIDs = append(filter.AssigneeIDs, uint(id))
+		}
+	}
+
+	ranges := []struct {
+		name string
+		dest *time.Time
+	}{
+		{"due_from", &filter.DueFrom},
+		{"due_to", &filter.DueTo},
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"updated_from", &filter.UpdatedFrom},
+		{"updated_to", &filter.UpdatedTo},
+	}
+	for _, r := range ranges {
+		t, ok := parseTimeValue(values.Get(r.name))
+		if !ok {
+			return filter, "invalid " + r.name + ", expected YYYY-MM-DD or RFC 3339"
+		}
+		*r.dest = t
+	}
+	filter.Text = values.Get("text")
+	filter.Query = values.Get("q")
+	filter.UserID = userID
+
+	var ok bool
+	if filter.LabelIDs, ok = parseIDList(values.Get("labels")); !ok {
+		return filter, "invalid labels"
+	}
+	switch values.Get("label_match") {
+	case "", "any":
+	case "all":
+		filter.MatchAllLabels = true
+	default:
+		return filter, "label_match must be any or all"
+	}
+	if filter.CustomFields, ok = parseCustomFieldConditions(values); !ok {
+		return filter, "invalid custom field filter"
+	}
+
+	for _, field := range parseList(values.Get("sort")) {
+		filter.Sort = append(filter.Sort, services.TaskSort{
+			Field: strings.TrimPrefix(field, "-"),
+			Desc:  strings.HasPrefix(field, "-"),
+		})
+	}
+	filter.Cursor = values.Get("cursor")
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return filter, "invalid limit"
+		}
+		filter.Limit = n
+	}
+
+	return filter, ""
+}
+
 func (c *TaskController) Create(ctx *gin.Context) {
 	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -40,9 +180,10 @@ func (c *TaskController) Create(ctx *gin.Context) {
 		return
 	}
 
-	task, err := c.taskService.Create(uint(projectID), input)
+	userID := ctx.GetUint("user_id")
+	task, err := c.taskService.Create(uint(projectID), userID, input)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -62,13 +203,147 @@ func (c *TaskController) Update(ctx *gin.Context) {
 		return
 	}
 
-	task, err := c.taskService.Update(uint(taskID), input)
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	task, err := c.taskService.Update(uint(taskID), userID, input, version)
+	if err == models.ErrVersionConflict {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+	if err != nil {
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	respondVersioned(ctx, http.StatusOK, task.Version, task)
+}
+
+// Patch applies an RFC 7396 merge patch: absent fields stay untouched and
+// null clears a field. If-Match works as for Update.
+func (c *TaskController) Patch(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var patch services.MergePatch
+	if err := ctx.ShouldBindJSON(&patch); err != nil || patch == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "patch must be a JSON object"})
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	task, err := c.taskService.Patch(uint(taskID), userID, patch, version)
+	if err == models.ErrVersionConflict {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+	if err != nil {
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	respondVersioned(ctx, http.StatusOK, task.Version, task)
+}
+
+// respondConflict answers a failed If-Match with 412 and the current task.
+func (c *TaskController) respondConflict(ctx *gin.Context, taskID uint) {
+	task, err := c.taskService.GetByID(taskID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	respondVersioned(ctx, http.StatusPreconditionFailed, task.Version, task)
+}
+
+// Bulk applies one update or delete to many tasks of the project. In
+// atomic mode a failing task rolls back the whole request, answered with
+// 422 and the per-task report.
+func (c *TaskController) Bulk(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.BulkTaskInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	result, err := c.taskService.Bulk(uint(projectID), userID, input)
+	if err == models.ErrBulkFailed {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "result": result})
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTaskQueryError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, task)
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Move relocates the task into the project given in the body.
+func (c *TaskController) Move(ctx *gin.Context) {
+	c.transfer(ctx, c.taskService.Move, http.StatusOK)
+}
+
+// Copy creates a copy of the task in the project given in the body.
+func (c *TaskController) Copy(ctx *gin.Context) {
+	c.transfer(ctx, c.taskService.Copy, http.StatusCreated)
+}
+
+func (c *TaskController) transfer(ctx *gin.Context, fn func(taskID, actorID uint, input services.TransferTaskInput) (*models.Task, error), status int) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	var input services.TransferTaskInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	task, err := fn(uint(taskID), userID, input)
+	if err != nil {
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	respondVersioned(ctx, status, task.Version, task)
 }
 
 func (c *TaskController) UpdateStatus(ctx *gin.Context) {
@@ -92,8 +367,20 @@ func (c *TaskController) UpdateStatus(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.taskService.UpdateStatus(uint(taskID), input.Status); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	err = c.taskService.UpdateStatus(uint(taskID), userID, input.Status, version)
+	if err == models.ErrVersionConflict {
+		c.respondConflict(ctx, uint(taskID))
+		return
+	}
+	if err != nil {
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -107,8 +394,9 @@ func (c *TaskController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.taskService.Delete(uint(taskID)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID := ctx.GetUint("user_id")
+	if err := c.taskService.Delete(uint(taskID), userID); err != nil {
+		ctx.JSON(taskErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -122,13 +410,47 @@ func (c *TaskController) GetProjectTasks(ctx *gin.Context) {
 		return
 	}
 
-	tasks, err := c.taskService.GetProjectTasks(uint(projectID))
+	filter, msg := parseTaskFilter(ctx.Request.URL.Query(), ctx.GetUint("user_id"))
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	page, err := c.taskService.GetProjectTasks(uint(projectID), filter)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondTaskQueryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// Search runs a task query such as
+// status != DONE AND assignee in (me, 12) ORDER BY due
+// over every project the caller can access.
+func (c *TaskController) Search(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	userID := ctx.GetUint("user_id")
+
+	page, err := c.taskService.Search(userID, ctx.Query("q"), ctx.Query("cursor"), limit)
+	if err != nil {
+		respondTaskQueryError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetByKey finds a task by its key, e.g. /api/tasks/by-key/WEB-42. Former
+// keys of moved or re-keyed tasks still resolve.
+func (c *TaskController) GetByKey(ctx *gin.Context) {
+	task, err := c.taskService.GetByKey(ctx.Param("key"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, tasks)
+	respondVersioned(ctx, http.StatusOK, task.Version, task)
 }
 
 func (c *TaskController) GetByID(ctx *gin.Context) {
@@ -144,5 +466,5 @@ func (c *TaskController) GetByID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, task)
+	respondVersioned(ctx, http.StatusOK, task.Version, task)
 } 
\ No newline at end of file
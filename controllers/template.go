@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type TemplateController struct {
+	templateService *services.TemplateService
+	validate        *validator.Validate
+}
+
+func NewTemplateController(templateService *services.TemplateService) *TemplateController {
+	return &TemplateController{
+		templateService: templateService,
+		validate:        validator.New(),
+	}
+}
+
+func templateErrorStatus(err error) int {
+	if status := customFieldErrorStatus(err); status != 0 {
+		return status
+	}
+	switch err {
+	case models.ErrTemplateNotFound, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrForbidden:
+		return http.StatusForbidden
+	}
+	return projectErrorStatus(err)
+}
+
+// bindProjectInput reads and validates the new project of a template or
+// clone request.
+func (c *TemplateController) bindProjectInput(ctx *gin.Context) (services.CreateProjectInput, bool) {
+	var input services.CreateProjectInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return input, false
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return input, false
+	}
+	return input, true
+}
+
+func (c *TemplateController) GetUserTemplates(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	templates, err := c.templateService.GetUserTemplates(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+func (c *TemplateController) GetByID(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	template, err := c.templateService.GetByID(uint(templateID), userID)
+	if err != nil {
+		ctx.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// CreateFromProject saves a project as a template.
+func (c *TemplateController) CreateFromProject(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var input services.CreateTemplateInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.validate.Struct(input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	template, err := c.templateService.CreateFromProject(uint(projectID), userID, input)
+	if err != nil {
+		ctx.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, template)
+}
+
+func (c *TemplateController) Delete(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	if err := c.templateService.Delete(uint(templateID), userID); err != nil {
+		ctx.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateProject creates a new project from a template.
+func (c *TemplateController) CreateProject(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	input, ok := c.bindProjectInput(ctx)
+	if !ok {
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	project, err := c.templateService.CreateProject(uint(templateID), userID, input)
+	if err != nil {
+		ctx.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, project)
+}
+
+// CloneProject copies a project's structure into a new project.
+func (c *TemplateController) CloneProject(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	input, ok := c.bindProjectInput(ctx)
+	if !ok {
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	project, err := c.templateService.CloneProject(uint(projectID), userID, input)
+	if err != nil {
+		ctx.JSON(templateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, project)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"taskive/models"
+	"taskive/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TrashController struct {
+	trashService *services.TrashService
+}
+
+func NewTrashController(trashService *services.TrashService) *TrashController {
+	return &TrashController{
+		trashService: trashService,
+	}
+}
+
+func trashErrorStatus(err error) int {
+	switch err {
+	case models.ErrNotInTrash, gorm.ErrRecordNotFound:
+		return http.StatusNotFound
+	case models.ErrParentTrashed, models.ErrProjectArchived:
+		return http.StatusConflict
+	case models.ErrForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func (c *TrashController) GetProjectTrash(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	trash, err := c.trashService.GetProjectTrash(uint(projectID), userID)
+	if err != nil {
+		ctx.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trash)
+}
+
+func (c *TrashController) GetTrashedProjects(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	projects, err := c.trashService.GetTrashedProjects(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, projects)
+}
+
+func (c *TrashController) RestoreProject(ctx *gin.Context) {
+	projectID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	project, err := c.trashService.RestoreProject(uint(projectID), auditMeta(ctx))
+	if err != nil {
+		ctx.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, project)
+}
+
+func (c *TrashController) RestoreTask(ctx *gin.Context) {
+	taskID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	task, err := c.trashService.RestoreTask(uint(taskID), userID)
+	if err != nil {
+		ctx.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+func (c *TrashController) RestoreComment(ctx *gin.Context) {
+	commentID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	comment, err := c.trashService.RestoreComment(uint(commentID), userID)
+	if err != nil {
+		ctx.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
@@ -9,6 +9,7 @@ import (
 	"taskive/models"
 	"taskive/routes"
 	"taskive/services"
+	"time"
 )
 
 func main() {
@@ -23,6 +24,12 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Initialize attachment storage
+	store, err := config.InitStorage()
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
 	// Initialize middleware with database connection
 	middlewares.InitMiddleware(db)
 
@@ -33,17 +40,58 @@ func main() {
 		&models.Task{},
 		&models.Comment{},
 		&models.Member{},
+		&models.Sprint{},
+		&models.TaskStatusChange{},
+		&models.TaskActivity{},
+		&models.AuditLog{},
+		&models.ChecklistItem{},
+		&models.Attachment{},
+		&models.Label{},
+		&models.CustomField{},
+		&models.CustomFieldValue{},
+		&models.SavedView{},
+		&models.SavedViewDefault{},
+		&models.ProjectTemplate{},
+		&models.TaskKeyAlias{},
+		&models.CommentRevision{},
+		&models.Mention{},
+		&models.SprintTask{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	if err := services.MigrateSearchIndexes(db); err != nil {
+		log.Fatal("Failed to create search indexes:", err)
+	}
+	if err := services.MigrateTaskKeys(db); err != nil {
+		log.Fatal("Failed to assign task keys:", err)
+	}
 
 	// Initialize services
 	authService := services.NewAuthService(db)
-	projectService := services.NewProjectService(db)
-	taskService := services.NewTaskService(db)
-	commentService := services.NewCommentService(db)
+	projectService := services.NewProjectService(db, store)
+	taskService := services.NewTaskService(db, store, config.AppConfig.AttachmentProjectQuota)
+	commentService := services.NewCommentService(db, store, time.Duration(config.AppConfig.CommentEditWindowMinutes)*time.Minute)
 	invitationService := services.NewInvitationService(db)
+	sprintService := services.NewSprintService(db)
+	reportService := services.NewReportService(db)
+	analyticsService := services.NewAnalyticsService(db)
+	activityService := services.NewActivityService(db)
+	auditService := services.NewAuditService(db)
+	checklistService := services.NewChecklistService(db)
+	attachmentService := services.NewAttachmentService(db, store, services.AttachmentLimits{
+		MaxSize:      config.AppConfig.AttachmentMaxSize,
+		ProjectQuota: config.AppConfig.AttachmentProjectQuota,
+		AllowedTypes: config.AppConfig.AttachmentAllowedTypes,
+	}, config.AppConfig.AttachmentSigningKey)
+	labelService := services.NewLabelService(db)
+	customFieldService := services.NewCustomFieldService(db)
+	savedViewService := services.NewSavedViewService(db)
+	searchService := services.NewSearchService(db)
+	trashService := services.NewTrashService(db, store, time.Duration(config.AppConfig.TrashRetentionDays)*24*time.Hour)
+	trashService.StartPurgeJob()
+	templateService := services.NewTemplateService(db)
+	mentionService := services.NewMentionService(db)
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authService)
@@ -51,6 +99,20 @@ func main() {
 	taskController := controllers.NewTaskController(taskService)
 	commentController := controllers.NewCommentController(commentService)
 	invitationController := controllers.NewInvitationController(invitationService)
+	sprintController := controllers.NewSprintController(sprintService)
+	reportController := controllers.NewReportController(reportService)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	activityController := controllers.NewActivityController(activityService)
+	auditController := controllers.NewAuditController(auditService)
+	checklistController := controllers.NewChecklistController(checklistService)
+	attachmentController := controllers.NewAttachmentController(attachmentService, config.AppConfig.AttachmentMaxSize)
+	labelController := controllers.NewLabelController(labelService)
+	customFieldController := controllers.NewCustomFieldController(customFieldService)
+	savedViewController := controllers.NewSavedViewController(savedViewService, taskService)
+	searchController := controllers.NewSearchController(searchService)
+	trashController := controllers.NewTrashController(trashService)
+	templateController := controllers.NewTemplateController(templateService)
+	mentionController := controllers.NewMentionController(mentionService)
 
 	// Setup router
 	router := routes.SetupRouter(
@@ -59,6 +121,20 @@ func main() {
 		taskController,
 		commentController,
 		invitationController,
+		sprintController,
+		reportController,
+		analyticsController,
+		activityController,
+		auditController,
+		checklistController,
+		attachmentController,
+		labelController,
+		customFieldController,
+		savedViewController,
+		searchController,
+		trashController,
+		templateController,
+		mentionController,
 	)
 
 	// Start server
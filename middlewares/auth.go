@@ -52,6 +52,25 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if db == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection not initialized"})
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, c.GetUint("user_id")).Error; err != nil || !user.IsAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func RoleMiddleware(allowedRoles ...models.MemberRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if db == nil {
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ThumbnailStatus string
+
+const (
+	ThumbnailStatusNone    ThumbnailStatus = "NONE"
+	ThumbnailStatusPending ThumbnailStatus = "PENDING"
+	ThumbnailStatusReady   ThumbnailStatus = "READY"
+	ThumbnailStatusFailed  ThumbnailStatus = "FAILED"
+)
+
+type Attachment struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ProjectID   uint      `gorm:"index" json:"project_id"`
+	TaskID      uint      `gorm:"index" json:"task_id"`
+	CommentID   *uint     `gorm:"index" json:"comment_id"`
+	UploaderID  uint      `json:"uploader_id"`
+	Uploader    User      `gorm:"foreignKey:UploaderID" json:"uploader"`
+	FileName    string    `gorm:"not null" json:"file_name"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	ThumbnailStatus ThumbnailStatus `gorm:"type:varchar(20);default:'NONE'" json:"thumbnail_status"`
+	ThumbnailKey    string          `json:"-"`
+	ThumbnailURL    string          `gorm:"-" json:"thumbnail_url,omitempty"`
+}
+
+// IsImage reports whether thumbnails can be generated for the attachment.
+func (a *Attachment) IsImage() bool {
+	switch a.ContentType {
+	case "image/png", "image/jpeg", "image/gif":
+		return true
+	}
+	return false
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	if a.ThumbnailStatus == "" {
+		a.ThumbnailStatus = ThumbnailStatusNone
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionRegister         = "auth.register"
+	AuditActionLogin            = "auth.login"
+	AuditActionLoginFailed      = "auth.login_failed"
+	AuditActionTokenCreated     = "auth.token_created"
+	AuditActionProjectDelete    = "project.deleted"
+	AuditActionProjectRestore   = "project.restored"
+	AuditActionProjectArchive   = "project.archived"
+	AuditActionProjectUnarchive = "project.unarchived"
+	AuditActionMemberInvite     = "member.invited"
+	AuditActionMemberAccept     = "member.accepted"
+	AuditActionMemberReject     = "member.rejected"
+	AuditActionMemberRemove     = "member.removed"
+	AuditActionMemberRole       = "member.role_changed"
+)
+
+const (
+	AuditTargetUser    = "user"
+	AuditTargetProject = "project"
+	AuditTargetMember  = "member"
+)
+
+// AuditSnapshot holds a JSON document describing a target before or after
+// an audited change.
+type AuditSnapshot json.RawMessage
+
+func (s AuditSnapshot) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+func (s *AuditSnapshot) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+	case []byte:
+		*s = append((*s)[:0], v...)
+	case string:
+		*s = AuditSnapshot(v)
+	default:
+		return fmt.Errorf("unsupported audit snapshot type %T", value)
+	}
+	return nil
+}
+
+func (s AuditSnapshot) MarshalJSON() ([]byte, error) {
+	if len(s) == 0 {
+		return []byte("null"), nil
+	}
+	return s, nil
+}
+
+// AuditLog is an append-only record of a security-relevant event. Rows can
+// be created but never updated or deleted through GORM.
+type AuditLog struct {
+	ID         uint          `gorm:"primarykey" json:"id"`
+	ActorID    *uint         `gorm:"index" json:"actor_id"`
+	IP         string        `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string        `json:"user_agent"`
+	Action     string        `gorm:"type:varchar(50);not null;index" json:"action"`
+	TargetType string        `gorm:"type:varchar(30);index:idx_audit_target" json:"target_type"`
+	TargetID   uint          `gorm:"index:idx_audit_target" json:"target_id"`
+	ProjectID  *uint         `gorm:"index" json:"project_id"`
+	Before     AuditSnapshot `gorm:"type:jsonb" json:"before"`
+	After      AuditSnapshot `gorm:"type:jsonb" json:"after"`
+	CreatedAt  time.Time     `gorm:"index" json:"created_at"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ChecklistItem struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	TaskID     uint       `gorm:"index" json:"task_id"`
+	Task       Task       `gorm:"foreignKey:TaskID" json:"-"`
+	Position   int        `gorm:"not null" json:"position"`
+	Text       string     `gorm:"not null" json:"text" validate:"required"`
+	Checked    bool       `gorm:"not null;default:false" json:"checked"`
+	CheckedAt  *time.Time `json:"checked_at"`
+	AssigneeID *uint      `json:"assignee_id"`
+	Assignee   *User      `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
+	DueDate    *time.Time `json:"due_date"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ChecklistSummary is the completion state of a task's checklist, e.g. 3/7.
+type ChecklistSummary struct {
+	Total   int64 `json:"total"`
+	Checked int64 `json:"checked"`
+}
+
+func (c *ChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -7,14 +7,18 @@ import (
 )
 
 type Comment struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	TaskID    uint      `json:"task_id"`
-	Task      Task      `gorm:"foreignKey:TaskID" json:"-"`
-	UserID    uint      `json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID" json:"user"`
-	Text      string    `gorm:"not null" json:"text" validate:"required"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint           `gorm:"primarykey" json:"id"`
+	TaskID    uint           `json:"task_id"`
+	Task      Task           `gorm:"foreignKey:TaskID" json:"-"`
+	ParentID  *uint          `gorm:"index" json:"parent_id"`
+	UserID    uint           `json:"user_id"`
+	User      User           `gorm:"foreignKey:UserID" json:"user"`
+	Text      string         `gorm:"not null" json:"text" validate:"required"`
+	EditedAt  *time.Time     `json:"edited_at"`
+	Mentions  []Mention      `gorm:"foreignKey:CommentID" json:"mentions,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 func (c *Comment) BeforeCreate(tx *gorm.DB) error {
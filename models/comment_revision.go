@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CommentRevision keeps a former text of an edited comment. EditorID is the
+// user whose edit replaced it and CreatedAt the time of that edit.
+type CommentRevision struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CommentID uint      `gorm:"index;not null" json:"comment_id"`
+	Text      string    `gorm:"not null" json:"text"`
+	EditorID  uint      `json:"editor_id"`
+	Editor    User      `gorm:"foreignKey:EditorID" json:"editor"`
+	CreatedAt time.Time `json:"created_at"`
+}
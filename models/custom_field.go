@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText         CustomFieldType = "TEXT"
+	CustomFieldNumber       CustomFieldType = "NUMBER"
+	CustomFieldDate         CustomFieldType = "DATE"
+	CustomFieldSingleSelect CustomFieldType = "SINGLE_SELECT"
+	CustomFieldMultiSelect  CustomFieldType = "MULTI_SELECT"
+	CustomFieldUser         CustomFieldType = "USER"
+	CustomFieldURL          CustomFieldType = "URL"
+	CustomFieldCheckbox     CustomFieldType = "CHECKBOX"
+)
+
+// StringList is a list of strings stored as a JSON array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported string list type %T", value)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+func (StringList) GormDataType() string {
+	return "jsonb"
+}
+
+// Contains reports whether the list holds the value.
+func (l StringList) Contains(value string) bool {
+	for _, item := range l {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+type CustomField struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	ProjectID uint            `gorm:"not null;uniqueIndex:idx_custom_field_project_name" json:"project_id"`
+	Project   Project         `gorm:"foreignKey:ProjectID" json:"-"`
+	Name      string          `gorm:"not null;uniqueIndex:idx_custom_field_project_name" json:"name"`
+	Type      CustomFieldType `gorm:"type:varchar(20);not null" json:"type"`
+	Options   StringList      `json:"options,omitempty"`
+	Required  bool            `gorm:"not null;default:false" json:"required"`
+	Position  int             `gorm:"not null" json:"position"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// IsSelect reports whether values must come from the field's options.
+func (f *CustomField) IsSelect() bool {
+	return f.Type == CustomFieldSingleSelect || f.Type == CustomFieldMultiSelect
+}
+
+func (f *CustomField) BeforeCreate(tx *gorm.DB) error {
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// CustomFieldValue holds a task's value for one custom field. Only the
+// column matching the field type is set so values can be filtered and
+// sorted in SQL.
+type CustomFieldValue struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	TaskID      uint       `gorm:"not null;uniqueIndex:idx_custom_field_value_task_field" json:"task_id"`
+	FieldID     uint       `gorm:"not null;uniqueIndex:idx_custom_field_value_task_field;index" json:"field_id"`
+	TextValue   *string    `json:"text_value,omitempty"`
+	NumberValue *float64   `json:"number_value,omitempty"`
+	DateValue   *time.Time `gorm:"type:date" json:"date_value,omitempty"`
+	BoolValue   *bool      `json:"bool_value,omitempty"`
+	UserValue   *uint      `json:"user_value,omitempty"`
+	ListValue   StringList `json:"list_value,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
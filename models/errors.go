@@ -10,4 +10,80 @@ var (
 	ErrUserNotFound     = errors.New("user not found")
 	ErrUnauthorized     = errors.New("unauthorized access")
 	ErrForbidden        = errors.New("forbidden access")
-) 
\ No newline at end of file
+
+	ErrSprintNotFound      = errors.New("sprint not found")
+	ErrSprintAlreadyActive = errors.New("project already has an active sprint")
+	ErrSprintNotPlanned    = errors.New("sprint is not in planned state")
+	ErrSprintNotActive     = errors.New("sprint is not active")
+	ErrSprintClosed        = errors.New("sprint is closed")
+	ErrSprintProject       = errors.New("sprint belongs to a different project")
+	ErrSprintRollover      = errors.New("unfinished tasks cannot roll over into the sprint being closed")
+
+	ErrInvalidReportRange = errors.New("invalid report date range")
+
+	ErrAuditLogImmutable = errors.New("audit log entries cannot be modified")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrLastOwner         = errors.New("project must keep at least one owner")
+
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrChecklistOrder        = errors.New("reorder must list every checklist item of the task exactly once")
+
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrAttachmentTooLarge  = errors.New("attachment exceeds the maximum file size")
+	ErrAttachmentType      = errors.New("attachment file type is not allowed")
+	ErrAttachmentQuota     = errors.New("project attachment quota exceeded")
+	ErrInvalidDownloadLink = errors.New("download link is invalid or expired")
+	ErrCoverNotImage       = errors.New("task cover must be an image attachment of the task")
+	ErrThumbnailNotReady   = errors.New("thumbnail is not available")
+
+	ErrLabelNotFound = errors.New("label not found")
+	ErrLabelExists   = errors.New("a label with this name already exists in the project")
+	ErrLabelProject  = errors.New("label belongs to a different project")
+	ErrLabelMerge    = errors.New("cannot merge a label into itself")
+	ErrLabelName     = errors.New("label name must not be blank")
+
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+	ErrCustomFieldExists   = errors.New("a custom field with this name already exists in the project")
+	ErrCustomFieldType     = errors.New("invalid custom field type")
+	ErrCustomFieldOptions  = errors.New("select fields need at least one distinct option")
+	ErrCustomFieldValue    = errors.New("invalid custom field value")
+	ErrCustomFieldRequired = errors.New("required custom field is missing")
+	ErrCustomFieldSort     = errors.New("tasks cannot be sorted by a multi-select field")
+
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidCursor = errors.New("invalid or outdated cursor")
+
+	ErrSavedViewNotFound = errors.New("saved view not found")
+
+	ErrSearchQuery = errors.New("search query is required")
+	ErrSearchType  = errors.New("invalid search type")
+
+	ErrNotInTrash    = errors.New("item is not in the trash")
+	ErrParentTrashed = errors.New("the containing project or task is in the trash, restore it first")
+
+	ErrProjectArchived = errors.New("project is archived and read-only, unarchive it first")
+
+	ErrTemplateNotFound = errors.New("project template not found")
+
+	ErrInvalidProjectKey = errors.New("project key must be 2-10 letters or digits starting with a letter")
+	ErrProjectKeyExists  = errors.New("project key is already in use")
+
+	ErrMoveSameProject   = errors.New("task already belongs to the target project")
+	ErrAssigneeNotMember = errors.New("assignee is not a member of the project")
+
+	ErrTaskNotFound  = errors.New("task not found")
+	ErrBulkTarget    = errors.New("give either task_ids or query")
+	ErrBulkNoChanges = errors.New("bulk update has no changes")
+	ErrBulkTooMany   = errors.New("bulk operations are limited to 500 tasks")
+	ErrBulkFailed    = errors.New("bulk operation failed, no task was changed")
+
+	ErrVersionConflict = errors.New("resource was modified since it was read")
+	ErrInvalidPatch    = errors.New("invalid patch")
+
+	ErrCommentNotAuthor  = errors.New("only the author can edit the comment")
+	ErrCommentEditWindow = errors.New("the edit window has passed, only project owners can edit the comment")
+	ErrCommentParent     = errors.New("parent comment not found on this task")
+	ErrCommentDepth      = errors.New("replies are nested too deeply")
+
+	ErrMentionNotFound = errors.New("mention not found")
+)
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const DefaultLabelColor = "#6B7280"
+
+type Label struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	ProjectID   uint      `gorm:"not null;uniqueIndex:idx_label_project_name" json:"project_id"`
+	Project     Project   `gorm:"foreignKey:ProjectID" json:"-"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_label_project_name" json:"name" validate:"required"`
+	Color       string    `gorm:"type:varchar(7);not null" json:"color"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (l *Label) BeforeCreate(tx *gorm.DB) error {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	if l.Color == "" {
+		l.Color = DefaultLabelColor
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Mention records that a project member was @mentioned in a comment or,
+// when CommentID is nil, in a task description. ReadAt is set once the
+// mentioned user has seen it.
+type Mention struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	User      User       `gorm:"foreignKey:UserID" json:"user"`
+	ProjectID uint       `gorm:"index;not null" json:"project_id"`
+	TaskID    uint       `gorm:"index;not null" json:"task_id"`
+	Task      *Task      `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	CommentID *uint      `gorm:"index" json:"comment_id"`
+	Comment   *Comment   `gorm:"foreignKey:CommentID" json:"comment,omitempty"`
+	AuthorID  uint       `json:"author_id"`
+	Author    *User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Handle    string     `gorm:"type:varchar(255);not null" json:"handle"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
@@ -7,17 +7,22 @@ import (
 )
 
 type Project struct {
-	ID          uint      `gorm:"primarykey" json:"id"`
-	Name        string    `gorm:"not null" json:"name" validate:"required"`
-	Description string    `json:"description"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
-	OwnerID     uint      `json:"owner_id"`
-	Owner       User      `gorm:"foreignKey:OwnerID" json:"owner"`
-	Tasks       []Task    `json:"tasks,omitempty"`
-	Members     []Member  `json:"members,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"not null" json:"name" validate:"required"`
+	Key         string         `gorm:"type:varchar(10);uniqueIndex" json:"key"`
+	Sequence    int            `gorm:"column:task_sequence;not null;default:0" json:"-"`
+	Description string         `json:"description"`
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	OwnerID     uint           `json:"owner_id"`
+	Owner       User           `gorm:"foreignKey:OwnerID" json:"owner"`
+	Tasks       []Task         `json:"tasks,omitempty"`
+	Members     []Member       `json:"members,omitempty"`
+	Version     int            `gorm:"not null;default:1" json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	ArchivedAt  *time.Time     `gorm:"index" json:"archived_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 func (p *Project) BeforeCreate(tx *gorm.DB) error {
@@ -27,5 +32,8 @@ func (p *Project) BeforeCreate(tx *gorm.DB) error {
 	if p.StartDate.IsZero() {
 		p.StartDate = time.Now()
 	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
 	return nil
 } 
\ No newline at end of file
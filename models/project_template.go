@@ -0,0 +1,96 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProjectTemplate is a reusable snapshot of a project's structure that new
+// projects can be created from.
+type ProjectTemplate struct {
+	ID          uint            `gorm:"primarykey" json:"id"`
+	Name        string          `gorm:"not null" json:"name"`
+	Description string          `json:"description"`
+	OwnerID     uint            `gorm:"index;not null" json:"owner_id"`
+	Owner       User            `gorm:"foreignKey:OwnerID" json:"owner"`
+	Content     TemplateContent `json:"content"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TemplateContent holds the labels, custom fields and tasks of a template.
+// Tasks refer to labels and custom fields by name, and due dates are kept
+// as day offsets from the project start date.
+type TemplateContent struct {
+	Labels       []TemplateLabel       `json:"labels"`
+	CustomFields []TemplateCustomField `json:"custom_fields"`
+	Tasks        []TemplateTask        `json:"tasks"`
+}
+
+type TemplateLabel struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type TemplateCustomField struct {
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Options  StringList      `json:"options,omitempty"`
+	Required bool            `json:"required"`
+}
+
+type TemplateTask struct {
+	Title         string                     `json:"title"`
+	Description   string                     `json:"description"`
+	Priority      TaskPriority               `json:"priority"`
+	StoryPoints   *int                       `json:"story_points,omitempty"`
+	DueOffsetDays *int                       `json:"due_offset_days,omitempty"`
+	Labels        []string                   `json:"labels,omitempty"`
+	CustomFields  map[string]json.RawMessage `json:"custom_fields,omitempty"`
+	Checklist     []TemplateChecklistItem    `json:"checklist,omitempty"`
+}
+
+type TemplateChecklistItem struct {
+	Text          string `json:"text"`
+	DueOffsetDays *int   `json:"due_offset_days,omitempty"`
+}
+
+func (c TemplateContent) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *TemplateContent) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = TemplateContent{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported template content type %T", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+func (TemplateContent) GormDataType() string {
+	return "jsonb"
+}
+
+func (t *ProjectTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedView is a named task listing. Filter holds the list query string
+// (e.g. "status=TODO&assignee=me") and Query an optional query language
+// expression; both are evaluated for the user running the view.
+type SavedView struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	ProjectID uint       `gorm:"index" json:"project_id"`
+	Project   Project    `gorm:"foreignKey:ProjectID" json:"-"`
+	OwnerID   uint       `gorm:"index" json:"owner_id"`
+	Owner     User       `gorm:"foreignKey:OwnerID" json:"owner"`
+	Name      string     `gorm:"not null" json:"name"`
+	Filter    string     `json:"filter"`
+	Query     string     `json:"query"`
+	Sort      string     `json:"sort"`
+	GroupBy   string     `gorm:"type:varchar(20)" json:"group_by"`
+	Columns   StringList `json:"columns"`
+	Shared    bool       `gorm:"not null;default:false" json:"shared"`
+	IsDefault bool       `gorm:"-" json:"is_default"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SavedViewDefault records the view a user opens first in a project.
+type SavedViewDefault struct {
+	UserID    uint `gorm:"primaryKey" json:"user_id"`
+	ProjectID uint `gorm:"primaryKey" json:"project_id"`
+	ViewID    uint `gorm:"not null;index" json:"view_id"`
+}
+
+func (v *SavedView) BeforeCreate(tx *gorm.DB) error {
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SprintState string
+
+const (
+	SprintStatePlanned SprintState = "PLANNED"
+	SprintStateActive  SprintState = "ACTIVE"
+	SprintStateClosed  SprintState = "CLOSED"
+)
+
+type Sprint struct {
+	ID        uint        `gorm:"primarykey" json:"id"`
+	ProjectID uint        `gorm:"index" json:"project_id"`
+	Project   Project     `gorm:"foreignKey:ProjectID" json:"-"`
+	Name      string      `gorm:"not null" json:"name" validate:"required"`
+	Goal      string      `json:"goal"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	State     SprintState `gorm:"type:varchar(20);default:'PLANNED'" json:"state"`
+	ClosedAt  *time.Time  `json:"closed_at,omitempty"`
+	Tasks     []Task      `json:"tasks,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+
+	// Filled in when the sprint is closed, so velocity survives rollover.
+	CommittedTasks  int64 `json:"committed_tasks"`
+	CommittedPoints int64 `json:"committed_points"`
+	CompletedTasks  int64 `json:"completed_tasks"`
+	CompletedPoints int64 `json:"completed_points"`
+}
+
+func (s *Sprint) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	if s.State == "" {
+		s.State = SprintStatePlanned
+	}
+	return nil
+}
+
+// SprintTask records that a task was in a sprint when the sprint closed,
+// so its reports keep the tasks that rolled over to another sprint.
+type SprintTask struct {
+	SprintID uint `gorm:"primaryKey;autoIncrement:false" json:"sprint_id"`
+	TaskID   uint `gorm:"primaryKey;autoIncrement:false;index" json:"task_id"`
+}
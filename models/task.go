@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -20,25 +21,67 @@ const (
 )
 
 type Task struct {
-	ID          uint         `gorm:"primarykey" json:"id"`
-	ProjectID   uint         `json:"project_id"`
-	Project     Project      `gorm:"foreignKey:ProjectID" json:"-"`
-	Title       string       `gorm:"not null" json:"title" validate:"required"`
-	Description string       `json:"description"`
-	Status      TaskStatus   `gorm:"type:varchar(20);default:'TODO'" json:"status"`
-	Priority    TaskPriority `gorm:"type:varchar(20);default:'MEDIUM'" json:"priority"`
-	DueDate     time.Time    `json:"due_date"`
-	AssigneeID  *uint        `json:"assignee_id"`
-	Assignee    *User        `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
-	Comments    []Comment    `json:"comments,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	ID                uint              `gorm:"primarykey" json:"id"`
+	ProjectID         uint              `json:"project_id"`
+	Number            int               `gorm:"index" json:"number"`
+	Key               string            `gorm:"type:varchar(24);uniqueIndex" json:"key"`
+	Project           Project           `gorm:"foreignKey:ProjectID" json:"-"`
+	Title             string            `gorm:"not null" json:"title" validate:"required"`
+	Description       string            `json:"description"`
+	Status            TaskStatus        `gorm:"type:varchar(20);default:'TODO'" json:"status"`
+	Priority          TaskPriority      `gorm:"type:varchar(20);default:'MEDIUM'" json:"priority"`
+	DueDate           time.Time         `json:"due_date"`
+	AssigneeID        *uint             `json:"assignee_id"`
+	Assignee          *User             `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
+	SprintID          *uint             `gorm:"index" json:"sprint_id"`
+	Sprint            *Sprint           `gorm:"foreignKey:SprintID" json:"-"`
+	StoryPoints       *int              `json:"story_points"`
+	Comments          []Comment         `json:"comments,omitempty"`
+	Checklist         *ChecklistSummary `gorm:"-" json:"checklist,omitempty"`
+	CoverAttachmentID *uint             `json:"cover_attachment_id"`
+	Labels            []Label           `gorm:"many2many:task_labels" json:"labels"`
+	CustomFields      map[string]any    `gorm:"-" json:"custom_fields"`
+	Version           int               `gorm:"not null;default:1" json:"version"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt    `gorm:"index" json:"deleted_at"`
+}
+
+// TaskKey formats a task key such as WEB-42.
+func TaskKey(projectKey string, number int) string {
+	return fmt.Sprintf("%s-%d", projectKey, number)
+}
+
+// NextTaskKey takes the next task number of the project and returns it with
+// the matching key. Incrementing the sequence locks the project row until
+// the transaction ends, so concurrent callers never share a number.
+func NextTaskKey(tx *gorm.DB, projectID uint) (int, string, error) {
+	var project struct {
+		Key          string
+		TaskSequence int
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).
+		Raw("UPDATE projects SET task_sequence = task_sequence + 1 WHERE id = ? RETURNING key, task_sequence", projectID).
+		Scan(&project).Error; err != nil {
+		return 0, "", err
+	}
+	return project.TaskSequence, TaskKey(project.Key, project.TaskSequence), nil
 }
 
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.CreatedAt.IsZero() {
 		t.CreatedAt = time.Now()
 	}
+	if t.Number == 0 {
+		number, key, err := NextTaskKey(tx, t.ProjectID)
+		if err != nil {
+			return err
+		}
+		t.Number, t.Key = number, key
+	}
+	if t.Version == 0 {
+		t.Version = 1
+	}
 	if t.Status == "" {
 		t.Status = TaskStatusTodo
 	}
@@ -46,4 +89,4 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 		t.Priority = TaskPriorityMedium
 	}
 	return nil
-} 
\ No newline at end of file
+}
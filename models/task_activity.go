@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaskActivityAction string
+
+const (
+	TaskActivityCreated       TaskActivityAction = "CREATED"
+	TaskActivityUpdated       TaskActivityAction = "UPDATED"
+	TaskActivityStatusChanged TaskActivityAction = "STATUS_CHANGED"
+	TaskActivityMoved         TaskActivityAction = "MOVED"
+	TaskActivityDeleted       TaskActivityAction = "DELETED"
+	TaskActivityRestored      TaskActivityAction = "RESTORED"
+)
+
+// TaskActivity is one entry of a task's change log. Field-level changes
+// carry the field name with its old and new value; nil means "no value".
+// Entries are kept after the task itself is deleted.
+type TaskActivity struct {
+	ID        uint               `gorm:"primarykey" json:"id"`
+	TaskID    uint               `gorm:"index" json:"task_id"`
+	ProjectID uint               `gorm:"index" json:"project_id"`
+	UserID    *uint              `json:"user_id"`
+	User      *User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Action    TaskActivityAction `gorm:"type:varchar(20);not null" json:"action"`
+	Field     string             `gorm:"type:varchar(50)" json:"field,omitempty"`
+	OldValue  *string            `json:"old_value"`
+	NewValue  *string            `json:"new_value"`
+	CreatedAt time.Time          `gorm:"index" json:"created_at"`
+}
+
+func (a *TaskActivity) BeforeCreate(tx *gorm.DB) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	return nil
+}
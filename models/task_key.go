@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TaskKeyAlias keeps a former key of a task resolvable after the task moved
+// to another project or its project key changed.
+type TaskKeyAlias struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Key       string    `gorm:"type:varchar(24);uniqueIndex;not null" json:"key"`
+	TaskID    uint      `gorm:"index;not null" json:"task_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
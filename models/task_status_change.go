@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskStatusChange records a single status transition of a task. A task's
+// first entry has an empty FromStatus and marks its creation.
+type TaskStatusChange struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	TaskID     uint       `gorm:"index" json:"task_id"`
+	ProjectID  uint       `gorm:"index" json:"project_id"`
+	FromStatus TaskStatus `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus   TaskStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	ChangedAt  time.Time  `gorm:"index" json:"changed_at"`
+}
+
+func (c *TaskStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ChangedAt.IsZero() {
+		c.ChangedAt = time.Now()
+	}
+	return nil
+}
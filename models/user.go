@@ -12,6 +12,7 @@ type User struct {
 	Name         string    `gorm:"not null" json:"name" validate:"required"`
 	Email        string    `gorm:"unique;not null" json:"email" validate:"required,email"`
 	PasswordHash string    `gorm:"not null" json:"-"`
+	IsAdmin      bool      `gorm:"not null;default:false" json:"is_admin"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
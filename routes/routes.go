@@ -3,6 +3,7 @@ package routes
 import (
 	"taskive/controllers"
 	"taskive/middlewares"
+	"taskive/models"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,20 @@ func SetupRouter(
 	taskController *controllers.TaskController,
 	commentController *controllers.CommentController,
 	invitationController *controllers.InvitationController,
+	sprintController *controllers.SprintController,
+	reportController *controllers.ReportController,
+	analyticsController *controllers.AnalyticsController,
+	activityController *controllers.ActivityController,
+	auditController *controllers.AuditController,
+	checklistController *controllers.ChecklistController,
+	attachmentController *controllers.AttachmentController,
+	labelController *controllers.LabelController,
+	customFieldController *controllers.CustomFieldController,
+	savedViewController *controllers.SavedViewController,
+	searchController *controllers.SearchController,
+	trashController *controllers.TrashController,
+	templateController *controllers.TemplateController,
+	mentionController *controllers.MentionController,
 ) *gin.Engine {
 	router := gin.Default()
 
@@ -21,7 +36,8 @@ func SetupRouter(
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:5173", "http://localhost:5174"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", "If-None-Match"}
+	config.ExposeHeaders = []string{"ETag"}
 	router.Use(cors.New(config))
 
 	// Middleware global
@@ -35,6 +51,10 @@ func SetupRouter(
 		auth.GET("/me", middlewares.AuthMiddleware(), authController.GetCurrentUser)
 	}
 
+	// Signed file downloads, authorized by the URL signature
+	router.GET("/files/attachments/:id", attachmentController.Download)
+	router.GET("/files/attachments/:id/thumbnail", attachmentController.DownloadThumbnail)
+
 	// Protected routes
 	api := router.Group("/api")
 	api.Use(middlewares.AuthMiddleware())
@@ -42,19 +62,65 @@ func SetupRouter(
 		// Users
 		api.GET("/users", authController.GetUserByEmail)
 
+		// Full-text search
+		api.GET("/search", searchController.Search)
+
 		// Projects
 		projects := api.Group("/projects")
 		{
 			projects.POST("", projectController.Create)
 			projects.GET("", projectController.GetUserProjects)
+			projects.GET("/trash", trashController.GetTrashedProjects)
 			projects.GET("/:id", projectController.GetByID)
 			projects.PUT("/:id", projectController.Update)
+			projects.PATCH("/:id", projectController.Patch)
 			projects.DELETE("/:id", projectController.Delete)
+			projects.POST("/:id/archive", middlewares.RoleMiddleware(models.MemberRoleOwner), projectController.Archive)
+			projects.POST("/:id/unarchive", middlewares.RoleMiddleware(models.MemberRoleOwner), projectController.Unarchive)
+			projects.POST("/:id/clone", templateController.CloneProject)
+			projects.POST("/:id/template", templateController.CreateFromProject)
 			projects.POST("/:id/invite", projectController.AddMember)
+			projects.PUT("/:id/members/:user_id", middlewares.RoleMiddleware(models.MemberRoleOwner), projectController.UpdateMemberRole)
+			projects.DELETE("/:id/members/:user_id", middlewares.RoleMiddleware(models.MemberRoleOwner), projectController.RemoveMember)
+
+			// Trash within project
+			projects.GET("/:id/trash", trashController.GetProjectTrash)
+			projects.POST("/:id/restore", middlewares.RoleMiddleware(models.MemberRoleOwner), trashController.RestoreProject)
 
 			// Tasks within project
 			projects.GET("/:id/tasks", taskController.GetProjectTasks)
 			projects.POST("/:id/tasks", taskController.Create)
+			projects.POST("/:id/tasks/bulk", taskController.Bulk)
+
+			// Labels within project
+			projects.GET("/:id/labels", labelController.GetProjectLabels)
+			projects.POST("/:id/labels", labelController.Create)
+			projects.POST("/:id/labels/merge", labelController.Merge)
+
+			// Custom fields within project
+			projects.GET("/:id/custom-fields", customFieldController.GetProjectFields)
+			projects.POST("/:id/custom-fields", customFieldController.Create)
+
+			// Saved views within project
+			projects.GET("/:id/views", savedViewController.GetProjectViews)
+			projects.POST("/:id/views", savedViewController.Create)
+			projects.GET("/:id/views/default", savedViewController.GetDefault)
+			projects.PUT("/:id/views/default", savedViewController.SetDefault)
+
+			// Sprints within project
+			projects.GET("/:id/sprints", sprintController.GetProjectSprints)
+			projects.POST("/:id/sprints", sprintController.Create)
+			projects.GET("/:id/backlog", sprintController.GetBacklog)
+
+			// Reports within project
+			projects.GET("/:id/reports/burndown", reportController.ProjectBurndown)
+			projects.GET("/:id/reports/burnup", reportController.ProjectBurnup)
+			projects.GET("/:id/reports/cumulative-flow", reportController.CumulativeFlow)
+			projects.GET("/:id/reports/velocity", reportController.Velocity)
+
+			// Flow analytics within project
+			projects.GET("/:id/analytics/flow", analyticsController.Flow)
+			projects.GET("/:id/analytics/control-chart", analyticsController.ControlChart)
 		}
 
 		// Invitations
@@ -68,22 +134,134 @@ func SetupRouter(
 		// Tasks
 		tasks := api.Group("/tasks")
 		{
+			tasks.GET("/search", taskController.Search)
+			tasks.GET("/by-key/:key", taskController.GetByKey)
 			tasks.GET("/:id", taskController.GetByID)
 			tasks.PUT("/:id", taskController.Update)
+			tasks.PATCH("/:id", taskController.Patch)
 			tasks.DELETE("/:id", taskController.Delete)
+			tasks.POST("/:id/restore", trashController.RestoreTask)
 			tasks.PATCH("/:id/status", taskController.UpdateStatus)
+			tasks.POST("/:id/move", taskController.Move)
+			tasks.POST("/:id/copy", taskController.Copy)
+			tasks.GET("/:id/activity", activityController.GetTaskActivity)
 
 			// Comments within task
 			tasks.GET("/:id/comments", commentController.GetTaskComments)
+			tasks.GET("/:id/comments/threads", commentController.GetTaskThreads)
 			tasks.POST("/:id/comments", commentController.Create)
+
+			// Checklist within task
+			tasks.GET("/:id/checklist", checklistController.GetTaskChecklist)
+			tasks.POST("/:id/checklist", checklistController.Create)
+			tasks.PUT("/:id/checklist/order", checklistController.Reorder)
+
+			// Attachments within task
+			tasks.GET("/:id/attachments", attachmentController.GetTaskAttachments)
+			tasks.POST("/:id/attachments", attachmentController.UploadToTask)
+			tasks.PUT("/:id/cover", attachmentController.SetTaskCover)
+
+			// Labels on task
+			tasks.PUT("/:id/labels", labelController.SetTaskLabels)
+			tasks.POST("/:id/labels/:label_id", labelController.AddTaskLabel)
+			tasks.DELETE("/:id/labels/:label_id", labelController.RemoveTaskLabel)
+
+			// Custom field values on task
+			tasks.PUT("/:id/custom-fields", customFieldController.SetTaskValues)
+		}
+
+		// Checklist items
+		checklistItems := api.Group("/checklist-items")
+		{
+			checklistItems.PUT("/:id", checklistController.Update)
+			checklistItems.DELETE("/:id", checklistController.Delete)
+		}
+
+		// Labels
+		labels := api.Group("/labels")
+		{
+			labels.PUT("/:id", labelController.Update)
+			labels.DELETE("/:id", labelController.Delete)
+		}
+
+		// Custom fields
+		customFields := api.Group("/custom-fields")
+		{
+			customFields.PUT("/:id", customFieldController.Update)
+			customFields.DELETE("/:id", customFieldController.Delete)
+		}
+
+		// Project templates
+		templates := api.Group("/templates")
+		{
+			templates.GET("", templateController.GetUserTemplates)
+			templates.GET("/:id", templateController.GetByID)
+			templates.DELETE("/:id", templateController.Delete)
+			templates.POST("/:id/projects", templateController.CreateProject)
+		}
+
+		// Saved views
+		views := api.Group("/views")
+		{
+			views.GET("/:id", savedViewController.GetByID)
+			views.PUT("/:id", savedViewController.Update)
+			views.DELETE("/:id", savedViewController.Delete)
+			views.GET("/:id/tasks", savedViewController.Run)
+		}
+
+		// Sprints
+		sprints := api.Group("/sprints")
+		{
+			sprints.GET("/:id", sprintController.GetByID)
+			sprints.PUT("/:id", sprintController.Update)
+			sprints.DELETE("/:id", sprintController.Delete)
+			sprints.POST("/:id/start", sprintController.Start)
+			sprints.POST("/:id/close", sprintController.Close)
+			sprints.GET("/:id/burndown", reportController.SprintBurndown)
+			sprints.GET("/:id/burnup", reportController.SprintBurnup)
+
+			// Tasks within sprint
+			sprints.GET("/:id/tasks", sprintController.GetSprintTasks)
+			sprints.POST("/:id/tasks", sprintController.AssignTasks)
+			sprints.DELETE("/:id/tasks", sprintController.UnassignTasks)
 		}
 
 		// Comments
 		comments := api.Group("/comments")
 		{
+			comments.PUT("/:id", commentController.Update)
 			comments.DELETE("/:id", commentController.Delete)
+			comments.GET("/:id/revisions", commentController.GetRevisions)
+			comments.GET("/:id/replies", commentController.GetReplies)
+			comments.POST("/:id/restore", trashController.RestoreComment)
+			comments.GET("/:id/attachments", attachmentController.GetCommentAttachments)
+			comments.POST("/:id/attachments", attachmentController.UploadToComment)
+		}
+
+		// Mentions
+		mentions := api.Group("/mentions")
+		{
+			mentions.GET("", mentionController.GetMyMentions)
+			mentions.POST("/read-all", mentionController.MarkAllRead)
+			mentions.POST("/:id/read", mentionController.MarkRead)
+		}
+
+		// Attachments
+		attachments := api.Group("/attachments")
+		{
+			attachments.GET("/:id", attachmentController.GetByID)
+			attachments.DELETE("/:id", attachmentController.Delete)
+			attachments.GET("/:id/url", attachmentController.GetDownloadURL)
+		}
+
+		// Admin
+		admin := api.Group("/admin")
+		admin.Use(middlewares.AdminMiddleware())
+		{
+			admin.GET("/audit-logs", auditController.List)
+			admin.GET("/audit-logs/export", auditController.Export)
 		}
 	}
 
 	return router
-} 
\ No newline at end of file
+}
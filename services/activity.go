@@ -0,0 +1,159 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ActivityService struct {
+	db *gorm.DB
+}
+
+func NewActivityService(db *gorm.DB) *ActivityService {
+	return &ActivityService{db: db}
+}
+
+const (
+	ActivityEntryChange  = "change"
+	ActivityEntryComment = "comment"
+)
+
+// ActivityEntry is one item of a task's activity feed: either a recorded
+// change or a comment.
+type ActivityEntry struct {
+	Type      string               `json:"type"`
+	CreatedAt time.Time            `json:"created_at"`
+	Change    *models.TaskActivity `json:"change,omitempty"`
+	Comment   *models.Comment      `json:"comment,omitempty"`
+}
+
+func actorRef(actorID uint) *uint {
+	if actorID == 0 {
+		return nil
+	}
+	return &actorID
+}
+
+func recordActivity(tx *gorm.DB, task *models.Task, actorID uint, action models.TaskActivityAction, field string, oldValue, newValue *string) error {
+	return tx.Create(&models.TaskActivity{
+		TaskID:    task.ID,
+		ProjectID: task.ProjectID,
+		UserID:    actorRef(actorID),
+		Action:    action,
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+	}).Error
+}
+
+func stringValue(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func timeValue(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	return stringValue(t.UTC().Format(time.RFC3339))
+}
+
+func uintValue(v *uint) *string {
+	if v == nil {
+		return nil
+	}
+	return stringValue(strconv.FormatUint(uint64(*v), 10))
+}
+
+func intValue(v *int) *string {
+	if v == nil {
+		return nil
+	}
+	return stringValue(strconv.Itoa(*v))
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// recordTaskChanges writes one activity entry per field that differs between
+// before and after.
+func recordTaskChanges(tx *gorm.DB, actorID uint, before, after *models.Task) error {
+	fields := []struct {
+		name     string
+		old, new *string
+	}{
+		{"title", stringValue(before.Title), stringValue(after.Title)},
+		{"description", stringValue(before.Description), stringValue(after.Description)},
+		{"status", stringValue(string(before.Status)), stringValue(string(after.Status))},
+		{"priority", stringValue(string(before.Priority)), stringValue(string(after.Priority))},
+		{"due_date", timeValue(before.DueDate), timeValue(after.DueDate)},
+		{"assignee_id", uintValue(before.AssigneeID), uintValue(after.AssigneeID)},
+		{"sprint_id", uintValue(before.SprintID), uintValue(after.SprintID)},
+		{"story_points", intValue(before.StoryPoints), intValue(after.StoryPoints)},
+	}
+
+	for _, field := range fields {
+		if sameValue(field.old, field.new) {
+			continue
+		}
+		action := models.TaskActivityUpdated
+		if field.name == "status" {
+			action = models.TaskActivityStatusChanged
+		}
+		if err := recordActivity(tx, after, actorID, action, field.name, field.old, field.new); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTaskActivity returns the task's change log merged with its comments,
+// oldest first.
+func (s *ActivityService) GetTaskActivity(taskID uint) ([]ActivityEntry, error) {
+	var activities []models.TaskActivity
+	if err := s.db.Where("task_id = ?", taskID).
+		Preload("User").
+		Order("created_at ASC, id ASC").
+		Find(&activities).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	if err := s.db.Where("task_id = ?", taskID).
+		Preload("User").
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	feed := make([]ActivityEntry, 0, len(activities)+len(comments))
+	for i := range activities {
+		feed = append(feed, ActivityEntry{
+			Type:      ActivityEntryChange,
+			CreatedAt: activities[i].CreatedAt,
+			Change:    &activities[i],
+		})
+	}
+	for i := range comments {
+		feed = append(feed, ActivityEntry{
+			Type:      ActivityEntryComment,
+			CreatedAt: comments[i].CreatedAt,
+			Comment:   &comments[i],
+		})
+	}
+
+	sort.SliceStable(feed, func(i, j int) bool {
+		return feed[i].CreatedAt.Before(feed[j].CreatedAt)
+	})
+	return feed, nil
+}
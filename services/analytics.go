@@ -0,0 +1,312 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// histogramEdges are the upper bounds, in hours, of the duration histogram buckets.
+var histogramEdges = []float64{4, 8, 24, 48, 72, 120, 168, 336, 720}
+
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+type FlowFilter struct {
+	From       time.Time
+	To         time.Time
+	AssigneeID *uint
+	Priority   models.TaskPriority
+}
+
+type HistogramBucket struct {
+	MinHours float64  `json:"min_hours"`
+	MaxHours *float64 `json:"max_hours"`
+	Count    int      `json:"count"`
+}
+
+// DurationStats describes a distribution of durations in hours.
+type DurationStats struct {
+	Count     int               `json:"count"`
+	Mean      float64           `json:"mean"`
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	P50       float64           `json:"p50"`
+	P75       float64           `json:"p75"`
+	P85       float64           `json:"p85"`
+	P95       float64           `json:"p95"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+type FlowMetrics struct {
+	LeadTime     DurationStats                       `json:"lead_time"`
+	CycleTime    DurationStats                       `json:"cycle_time"`
+	TimeInStatus map[models.TaskStatus]DurationStats `json:"time_in_status"`
+}
+
+type FlowGroup struct {
+	Key     string      `json:"key"`
+	Metrics FlowMetrics `json:"metrics"`
+}
+
+type FlowAnalytics struct {
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	Overall    FlowMetrics `json:"overall"`
+	ByAssignee []FlowGroup `json:"by_assignee"`
+	ByPriority []FlowGroup `json:"by_priority"`
+}
+
+// ControlChartPoint is one completed task, plotted by completion time.
+type ControlChartPoint struct {
+	TaskID         uint                `json:"task_id"`
+	Title          string              `json:"title"`
+	AssigneeID     *uint               `json:"assignee_id"`
+	Priority       models.TaskPriority `json:"priority"`
+	CreatedAt      time.Time           `json:"created_at"`
+	StartedAt      *time.Time          `json:"started_at"`
+	CompletedAt    time.Time           `json:"completed_at"`
+	LeadTimeHours  float64             `json:"lead_time_hours"`
+	CycleTimeHours *float64            `json:"cycle_time_hours"`
+}
+
+type completedTask struct {
+	point        ControlChartPoint
+	timeInStatus map[models.TaskStatus]float64
+}
+
+// completedTasks returns the project's tasks that were last moved to DONE
+// within the filter's date range, with their timings derived from the
+// status history. Tasks without recorded history are skipped.
+func (s *AnalyticsService) completedTasks(projectID uint, filter FlowFilter) ([]completedTask, error) {
+	from, to, err := normalizeRange(filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	end := to.AddDate(0, 0, 1)
+
+	query := s.db.Where("project_id = ? AND status = ?", projectID, models.TaskStatusDone)
+	if filter.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+
+	var tasks []models.Task
+	if err := query.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var changes []models.TaskStatusChange
+	if err := s.db.Where("task_id IN ? AND changed_at < ?", ids, end).
+		Order("changed_at ASC, id ASC").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	history := map[uint][]models.TaskStatusChange{}
+	for _, change := range changes {
+		history[change.TaskID] = append(history[change.TaskID], change)
+	}
+
+	var result []completedTask
+	for _, task := range tasks {
+		changes := history[task.ID]
+
+		doneIndex := -1
+		for i := len(changes) - 1; i >= 0; i-- {
+			if changes[i].ToStatus == models.TaskStatusDone {
+				doneIndex = i
+				break
+			}
+		}
+		if doneIndex < 0 || changes[doneIndex].ChangedAt.Before(from) {
+			continue
+		}
+		completedAt := changes[doneIndex].ChangedAt
+
+		point := ControlChartPoint{
+			TaskID:        task.ID,
+			Title:         task.Title,
+			AssigneeID:    task.AssigneeID,
+			Priority:      task.Priority,
+			CreatedAt:     task.CreatedAt,
+			CompletedAt:   completedAt,
+			LeadTimeHours: completedAt.Sub(task.CreatedAt).Hours(),
+		}
+
+		timeInStatus := map[models.TaskStatus]float64{}
+		for i := 0; i < doneIndex; i++ {
+			change := changes[i]
+			if change.ToStatus == models.TaskStatusInProgress && point.StartedAt == nil {
+				startedAt := change.ChangedAt
+				point.StartedAt = &startedAt
+			}
+			timeInStatus[change.ToStatus] += changes[i+1].ChangedAt.Sub(change.ChangedAt).Hours()
+		}
+		if point.StartedAt != nil {
+			cycle := completedAt.Sub(*point.StartedAt).Hours()
+			point.CycleTimeHours = &cycle
+		}
+
+		result = append(result, completedTask{point: point, timeInStatus: timeInStatus})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].point.CompletedAt.Before(result[j].point.CompletedAt)
+	})
+	return result, nil
+}
+
+func (s *AnalyticsService) Flow(projectID uint, filter FlowFilter) (*FlowAnalytics, error) {
+	tasks, err := s.completedTasks(projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, _ := normalizeRange(filter.From, filter.To)
+	analytics := &FlowAnalytics{
+		From:       from.Format("2006-01-02"),
+		To:         to.Format("2006-01-02"),
+		Overall:    flowMetrics(tasks),
+		ByAssignee: []FlowGroup{},
+		ByPriority: []FlowGroup{},
+	}
+
+	byAssignee := map[string][]completedTask{}
+	byPriority := map[string][]completedTask{}
+	for _, task := range tasks {
+		assignee := "unassigned"
+		if task.point.AssigneeID != nil {
+			assignee = fmt.Sprint(*task.point.AssigneeID)
+		}
+		byAssignee[assignee] = append(byAssignee[assignee], task)
+		byPriority[string(task.point.Priority)] = append(byPriority[string(task.point.Priority)], task)
+	}
+	analytics.ByAssignee = flowGroups(byAssignee)
+	analytics.ByPriority = flowGroups(byPriority)
+
+	return analytics, nil
+}
+
+func (s *AnalyticsService) ControlChart(projectID uint, filter FlowFilter) ([]ControlChartPoint, error) {
+	tasks, err := s.completedTasks(projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]ControlChartPoint, 0, len(tasks))
+	for _, task := range tasks {
+		points = append(points, task.point)
+	}
+	return points, nil
+}
+
+func flowGroups(groups map[string][]completedTask) []FlowGroup {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]FlowGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, FlowGroup{Key: key, Metrics: flowMetrics(groups[key])})
+	}
+	return result
+}
+
+func flowMetrics(tasks []completedTask) FlowMetrics {
+	var lead, cycle []float64
+	inStatus := map[models.TaskStatus][]float64{}
+	for _, task := range tasks {
+		lead = append(lead, task.point.LeadTimeHours)
+		if task.point.CycleTimeHours != nil {
+			cycle = append(cycle, *task.point.CycleTimeHours)
+		}
+		for status, hours := range task.timeInStatus {
+			inStatus[status] = append(inStatus[status], hours)
+		}
+	}
+
+	metrics := FlowMetrics{
+		LeadTime:     durationStats(lead),
+		CycleTime:    durationStats(cycle),
+		TimeInStatus: map[models.TaskStatus]DurationStats{},
+	}
+	for status, values := range inStatus {
+		metrics.TimeInStatus[status] = durationStats(values)
+	}
+	return metrics
+}
+
+func durationStats(values []float64) DurationStats {
+	stats := DurationStats{Count: len(values), Histogram: histogram(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	stats.Mean = sum / float64(len(sorted))
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.P50 = percentile(sorted, 50)
+	stats.P75 = percentile(sorted, 75)
+	stats.P85 = percentile(sorted, 85)
+	stats.P95 = percentile(sorted, 95)
+	return stats
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func histogram(values []float64) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(histogramEdges)+1)
+	lower := 0.0
+	for i, edge := range histogramEdges {
+		upper := edge
+		buckets[i] = HistogramBucket{MinHours: lower, MaxHours: &upper}
+		lower = edge
+	}
+	buckets[len(histogramEdges)] = HistogramBucket{MinHours: lower}
+
+	for _, v := range values {
+		i := sort.SearchFloat64s(histogramEdges, v)
+		if i < len(histogramEdges) && histogramEdges[i] == v {
+			i++
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"taskive/storage"
+	"taskive/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var serviceLogger = utils.NewLogger()
+
+type AttachmentLimits struct {
+	MaxSize      int64
+	ProjectQuota int64
+	AllowedTypes []string
+}
+
+const (
+	downloadVariantFile      = "file"
+	downloadVariantThumbnail = "thumbnail"
+
+	thumbnailURLTTL = time.Hour
+)
+
+type AttachmentService struct {
+	db         *gorm.DB
+	store      storage.Storage
+	limits     AttachmentLimits
+	signingKey []byte
+	thumbnails chan uint
+}
+
+func NewAttachmentService(db *gorm.DB, store storage.Storage, limits AttachmentLimits, signingKey string) *AttachmentService {
+	s := &AttachmentService{
+		db:         db,
+		store:      store,
+		limits:     limits,
+		signingKey: []byte(signingKey),
+	}
+	s.startThumbnailWorkers()
+	return s
+}
+
+// UploadInput describes a new attachment. For comment attachments only
+// CommentID is needed; the task is taken from the comment.
+type UploadInput struct {
+	TaskID     uint
+	CommentID  *uint
+	UploaderID uint
+	FileName   string
+	Size       int64
+	Body       io.Reader
+}
+
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// purgeAttachments deletes the attachment rows matching the condition
+// inside tx, clears task covers pointing at them and returns the storage
+// keys of their files and thumbnails. Callers remove the blobs with
+// removeBlobs once the transaction has committed.
+func purgeAttachments(tx *gorm.DB, query interface{}, args ...interface{}) ([]string, error) {
+	var rows []struct {
+		ID           uint
+		StorageKey   string
+		ThumbnailKey string
+	}
+	if err := tx.Model(&models.Attachment{}).
+		Select("id, storage_key, thumbnail_key").
+		Where(query, args...).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		keys = append(keys, row.StorageKey)
+		if row.ThumbnailKey != "" {
+			keys = append(keys, row.ThumbnailKey)
+		}
+	}
+
+	if err := tx.Unscoped().Model(&models.Task{}).
+		Where("cover_attachment_id IN ?", ids).
+		Updates(map[string]interface{}{
+			"cover_attachment_id": nil,
+			"version":             gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// removeBlobs deletes stored objects on a best-effort basis. Failures are
+// logged and leave orphaned blobs behind, never dangling rows.
+func removeBlobs(store storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(context.Background(), key); err != nil {
+			serviceLogger.LogError("STORAGE", fmt.Sprintf("failed to delete object %s: %v", key, err))
+		}
+	}
+}
+
+func newStorageKey(projectID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("projects/%d/attachments/%s", projectID, hex.EncodeToString(buf)), nil
+}
+
+func (s *AttachmentService) allowedType(contentType string) bool {
+	for _, allowed := range s.limits.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AttachmentService) Upload(input UploadInput) (*models.Attachment, error) {
+	if s.limits.MaxSize > 0 && input.Size > s.limits.MaxSize {
+		return nil, models.ErrAttachmentTooLarge
+	}
+
+	if input.CommentID != nil {
+		var comment models.Comment
+		if err := s.db.Select("id, task_id").First(&comment, *input.CommentID).Error; err != nil {
+			return nil, err
+		}
+		input.TaskID = comment.TaskID
+	}
+
+	var task models.Task
+	if err := s.db.Select("id, project_id").First(&task, input.TaskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	// Sniff the type from the content instead of trusting the client.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(input.Body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := strings.SplitN(http.DetectContentType(head), ";", 2)[0]
+	if !s.allowedType(contentType) {
+		return nil, models.ErrAttachmentType
+	}
+
+	key, err := newStorageKey(task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	body := io.MultiReader(bytes.NewReader(head), input.Body)
+	if err := s.store.Put(context.Background(), key, body, input.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		ProjectID:   task.ProjectID,
+		TaskID:      task.ID,
+		CommentID:   input.CommentID,
+		UploaderID:  input.UploaderID,
+		FileName:    filepath.Base(input.FileName),
+		ContentType: contentType,
+		Size:        input.Size,
+		StorageKey:  key,
+	}
+	if attachment.IsImage() {
+		attachment.ThumbnailStatus = models.ThumbnailStatusPending
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkAttachmentQuota(tx, task.ProjectID, s.limits.ProjectQuota, input.Size); err != nil {
+			return err
+		}
+		return tx.Create(attachment).Error
+	})
+	if err != nil {
+		removeBlobs(s.store, []string{key})
+		return nil, err
+	}
+	if attachment.IsImage() {
+		s.queueThumbnail(attachment.ID)
+	}
+
+	if err := s.db.Preload("Uploader").First(attachment, attachment.ID).Error; err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// checkAttachmentQuota returns ErrAttachmentQuota when size more bytes of
+// attachments would take the project over quota. A zero quota is unlimited.
+// tx must be a transaction: the project row stays locked until it ends, so
+// concurrent checks cannot together go over quota.
+func checkAttachmentQuota(tx *gorm.DB, projectID uint, quota, size int64) error {
+	if quota <= 0 {
+		return nil
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		First(&models.Project{}, projectID).Error; err != nil {
+		return err
+	}
+	var used int64
+	if err := tx.Model(&models.Attachment{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("project_id = ?", projectID).
+		Scan(&used).Error; err != nil {
+		return err
+	}
+	if used+size > quota {
+		return models.ErrAttachmentQuota
+	}
+	return nil
+}
+
+// attachThumbnailURLs fills ThumbnailURL for attachments whose thumbnail is ready.
+func (s *AttachmentService) attachThumbnailURLs(attachments []models.Attachment) {
+	expires := time.Now().Add(thumbnailURLTTL).Unix()
+	for i := range attachments {
+		if attachments[i].ThumbnailStatus == models.ThumbnailStatusReady {
+			attachments[i].ThumbnailURL = s.signedPath(attachments[i].ID, downloadVariantThumbnail, expires)
+		}
+	}
+}
+
+func (s *AttachmentService) GetByID(attachmentID uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := s.db.Preload("Uploader").First(&attachment, attachmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	attachments := []models.Attachment{attachment}
+	s.attachThumbnailURLs(attachments)
+	return &attachments[0], nil
+}
+
+func (s *AttachmentService) GetTaskAttachments(taskID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	liveComments := s.db.Model(&models.Comment{}).Select("id").Where("task_id = ?", taskID)
+	err := s.db.Where("task_id = ? AND (comment_id IS NULL OR comment_id IN (?))", taskID, liveComments).
+		Preload("Uploader").
+		Order("created_at ASC").
+		Find(&attachments).Error
+	s.attachThumbnailURLs(attachments)
+	return attachments, err
+}
+
+func (s *AttachmentService) GetCommentAttachments(commentID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := s.db.Where("comment_id = ?", commentID).
+		Preload("Uploader").
+		Order("created_at ASC").
+		Find(&attachments).Error
+	s.attachThumbnailURLs(attachments)
+	return attachments, err
+}
+
+// SetTaskCover picks an image attachment of the task as its cover, or
+// removes the cover when attachmentID is nil.
+func (s *AttachmentService) SetTaskCover(taskID uint, attachmentID *uint) (*models.Task, error) {
+	var task models.Task
+	if err := s.db.First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	if attachmentID != nil {
+		attachment, err := s.GetByID(*attachmentID)
+		if err != nil {
+			return nil, err
+		}
+		if attachment.TaskID != task.ID || !attachment.IsImage() {
+			return nil, models.ErrCoverNotImage
+		}
+	}
+
+	if err := s.db.Model(&task).Updates(map[string]interface{}{
+		"cover_attachment_id": attachmentID,
+		"version":             gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return nil, err
+	}
+	task.CoverAttachmentID = attachmentID
+	task.Version++
+	return &task, nil
+}
+
+func (s *AttachmentService) Delete(attachmentID uint) error {
+	var keys []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var attachment models.Attachment
+		if err := tx.Select("id, project_id").First(&attachment, attachmentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrAttachmentNotFound
+			}
+			return err
+		}
+		if err := checkProjectWritable(tx, attachment.ProjectID); err != nil {
+			return err
+		}
+
+		var err error
+		keys, err = purgeAttachments(tx, "id = ?", attachmentID)
+		if err == nil && len(keys) == 0 {
+			return models.ErrAttachmentNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	removeBlobs(s.store, keys)
+	return nil
+}
+
+func (s *AttachmentService) signature(attachmentID uint, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%d:%s:%d", attachmentID, variant, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *AttachmentService) signedPath(attachmentID uint, variant string, expires int64) string {
+	path := fmt.Sprintf("/files/attachments/%d", attachmentID)
+	if variant == downloadVariantThumbnail {
+		path += "/thumbnail"
+	}
+	return fmt.Sprintf("%s?expires=%d&signature=%s", path, expires, s.signature(attachmentID, variant, expires))
+}
+
+// SignDownload returns a download URL for the attachment that works without
+// authentication until it expires. Only accepted project members get one.
+func (s *AttachmentService) SignDownload(attachmentID, userID uint, ttl time.Duration) (*SignedURL, error) {
+	attachment, err := s.GetByID(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := isProjectMember(s.db, attachment.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, models.ErrForbidden
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	url := s.signedPath(attachmentID, downloadVariantFile, expiresAt.Unix())
+	return &SignedURL{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// OpenSigned validates a signed download link and opens the attachment, or
+// its thumbnail when thumbnail is true. The caller must close the returned
+// reader.
+func (s *AttachmentService) OpenSigned(attachmentID uint, thumbnail bool, expires, signature string) (*models.Attachment, io.ReadCloser, error) {
+	variant := downloadVariantFile
+	if thumbnail {
+		variant = downloadVariantThumbnail
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, nil, models.ErrInvalidDownloadLink
+	}
+	expected := s.signature(attachmentID, variant, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, nil, models.ErrInvalidDownloadLink
+	}
+
+	attachment, err := s.GetByID(attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailStatus != models.ThumbnailStatusReady {
+			return nil, nil, models.ErrThumbnailNotReady
+		}
+		key = attachment.ThumbnailKey
+	}
+	body, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		if err == storage.ErrObjectNotFound {
+			return nil, nil, models.ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, body, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"encoding/json"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxAuditPageSize = 200
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// AuditMeta describes who performed an audited request and from where.
+type AuditMeta struct {
+	ActorID   uint
+	IP        string
+	UserAgent string
+}
+
+type AuditFilter struct {
+	ActorID    *uint
+	ProjectID  *uint
+	TargetID   *uint
+	Action     string
+	TargetType string
+	From       time.Time
+	To         time.Time
+}
+
+type AuditPage struct {
+	Items    []models.AuditLog `json:"items"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+func auditSnapshot(v interface{}) (models.AuditSnapshot, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return models.AuditSnapshot(data), nil
+}
+
+// recordAudit appends an audit entry using tx, so it commits or rolls back
+// together with the change it describes.
+func recordAudit(tx *gorm.DB, meta AuditMeta, action, targetType string, targetID uint, projectID *uint, before, after interface{}) error {
+	beforeSnapshot, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterSnapshot, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.AuditLog{
+		ActorID:    actorRef(meta.ActorID),
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		ProjectID:  projectID,
+		Before:     beforeSnapshot,
+		After:      afterSnapshot,
+	}).Error
+}
+
+func (s *AuditService) query(filter AuditFilter) *gorm.DB {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
+
+func (s *AuditService) List(filter AuditFilter, page, pageSize int) (*AuditPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxAuditPageSize {
+		pageSize = 50
+	}
+
+	result := &AuditPage{Items: []models.AuditLog{}, Page: page, PageSize: pageSize}
+	if err := s.query(filter).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.query(filter).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&result.Items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Export streams every entry matching filter, oldest first, to fn in batches.
+func (s *AuditService) Export(filter AuditFilter, fn func([]models.AuditLog) error) error {
+	var batch []models.AuditLog
+	return s.query(filter).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
@@ -28,7 +28,7 @@ type LoginInput struct {
 	Password string `json:"password" validate:"required"`
 }
 
-func (s *AuthService) Register(input RegisterInput) (*models.User, error) {
+func (s *AuthService) Register(input RegisterInput, meta AuditMeta) (*models.User, error) {
 	// Check if email exists using a custom query to avoid GORM logging
 	var count int64
 	if err := s.db.Model(&models.User{}).Where("email = ?", input.Email).Count(&count).Error; err != nil {
@@ -47,29 +47,47 @@ func (s *AuthService) Register(input RegisterInput) (*models.User, error) {
 		return nil, err
 	}
 
-	if err := s.db.Create(user).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		meta.ActorID = user.ID
+		return recordAudit(tx, meta, models.AuditActionRegister, models.AuditTargetUser, user.ID, nil, nil, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *AuthService) Login(input LoginInput) (string, *models.User, error) {
+func (s *AuthService) Login(input LoginInput, meta AuditMeta) (string, *models.User, error) {
+	failed := map[string]string{"email": input.Email}
+
 	var user models.User
 	if err := s.db.Where("email = ?", input.Email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
+			failed["reason"] = models.ErrUserNotFound.Error()
+			if err := recordAudit(s.db, meta, models.AuditActionLoginFailed, models.AuditTargetUser, 0, nil, nil, failed); err != nil {
+				return "", nil, err
+			}
 			return "", nil, models.ErrUserNotFound
 		}
 		return "", nil, err
 	}
 
 	if !user.CheckPassword(input.Password) {
+		failed["reason"] = models.ErrInvalidPassword.Error()
+		if err := recordAudit(s.db, meta, models.AuditActionLoginFailed, models.AuditTargetUser, user.ID, nil, nil, failed); err != nil {
+			return "", nil, err
+		}
 		return "", nil, models.ErrInvalidPassword
 	}
 
+	expiresAt := time.Now().Add(time.Hour * 24)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"exp":     expiresAt.Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
@@ -77,6 +95,18 @@ func (s *AuthService) Login(input LoginInput) (string, *models.User, error) {
 		return "", nil, err
 	}
 
+	meta.ActorID = user.ID
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := recordAudit(tx, meta, models.AuditActionLogin, models.AuditTargetUser, user.ID, nil, nil, nil); err != nil {
+			return err
+		}
+		token := map[string]interface{}{"type": "jwt", "expires_at": expiresAt}
+		return recordAudit(tx, meta, models.AuditActionTokenCreated, models.AuditTargetUser, user.ID, nil, nil, token)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
 	return tokenString, &user, nil
 }
 
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"encoding/json"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ChecklistService struct {
+	db *gorm.DB
+}
+
+func NewChecklistService(db *gorm.DB) *ChecklistService {
+	return &ChecklistService{db: db}
+}
+
+type CreateChecklistItemInput struct {
+	Text       string     `json:"text" validate:"required"`
+	AssigneeID *uint      `json:"assignee_id"`
+	DueDate    *time.Time `json:"due_date"`
+}
+
+// UpdateChecklistItemInput changes only the fields that are present.
+// AssigneeID and DueDate are kept raw so that an explicit null, which
+// clears them, can be told apart from leaving them out.
+type UpdateChecklistItemInput struct {
+	Text       string          `json:"text"`
+	Checked    *bool           `json:"checked"`
+	AssigneeID json.RawMessage `json:"assignee_id"`
+	DueDate    json.RawMessage `json:"due_date"`
+}
+
+type ReorderChecklistInput struct {
+	ItemIDs []uint `json:"item_ids" validate:"required,min=1"`
+}
+
+// attachChecklistSummaries fills Task.Checklist for every task with a
+// single grouped query.
+func attachChecklistSummaries(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []struct {
+		TaskID  uint
+		Total   int64
+		Checked int64
+	}
+	if err := db.Model(&models.ChecklistItem{}).
+		Select("task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE checked) AS checked").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	summaries := make(map[uint]*models.ChecklistSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.TaskID] = &models.ChecklistSummary{Total: row.Total, Checked: row.Checked}
+	}
+	for i := range tasks {
+		if summary, ok := summaries[tasks[i].ID]; ok {
+			tasks[i].Checklist = summary
+		} else {
+			tasks[i].Checklist = &models.ChecklistSummary{}
+		}
+	}
+	return nil
+}
+
+func (s *ChecklistService) GetTaskChecklist(taskID uint) ([]models.ChecklistItem, error) {
+	var items []models.ChecklistItem
+	err := s.db.Where("task_id = ?", taskID).
+		Preload("Assignee").
+		Order("position ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+func (s *ChecklistService) Create(taskID uint, input CreateChecklistItemInput) (*models.ChecklistItem, error) {
+	item := &models.ChecklistItem{
+		TaskID:     taskID,
+		Text:       input.Text,
+		AssigneeID: input.AssigneeID,
+		DueDate:    input.DueDate,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.Select("id, project_id").First(&task, taskID).Error; err != nil {
+			return err
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+
+		var last struct{ Position *int }
+		if err := tx.Model(&models.ChecklistItem{}).
+			Select("MAX(position) AS position").
+			Where("task_id = ?", taskID).
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		if last.Position != nil {
+			item.Position = *last.Position + 1
+		}
+
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, taskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *ChecklistService) Update(itemID uint, input UpdateChecklistItemInput) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	if err := s.db.First(&item, itemID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+	if err := checkTaskWritable(s.db, item.TaskID); err != nil {
+		return nil, err
+	}
+
+	if input.Text != "" {
+		item.Text = input.Text
+	}
+	if input.Checked != nil && *input.Checked != item.Checked {
+		item.Checked = *input.Checked
+		item.CheckedAt = nil
+		if item.Checked {
+			now := time.Now()
+			item.CheckedAt = &now
+		}
+	}
+	if input.AssigneeID != nil {
+		assigneeID, err := patchID("assignee_id", input.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		item.AssigneeID = assigneeID
+	}
+	if input.DueDate != nil {
+		dueDate, err := patchTime("due_date", input.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		item.DueDate = nil
+		if !dueDate.IsZero() {
+			item.DueDate = &dueDate
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, item.TaskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// Delete removes an item and closes the gap it leaves in the ordering.
+func (s *ChecklistService) Delete(itemID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var item models.ChecklistItem
+		if err := tx.First(&item, itemID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrChecklistItemNotFound
+			}
+			return err
+		}
+		if err := checkTaskWritable(tx, item.TaskID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChecklistItem{}).
+			Where("task_id = ? AND position > ?", item.TaskID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, item.TaskID)
+	})
+}
+
+// Reorder sets the order of a task's checklist. itemIDs must contain every
+// item of the task exactly once.
+func (s *ChecklistService) Reorder(taskID uint, itemIDs []uint) ([]models.ChecklistItem, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkTaskWritable(tx, taskID); err != nil {
+			return err
+		}
+		var existing []uint
+		if err := tx.Model(&models.ChecklistItem{}).
+			Where("task_id = ?", taskID).
+			Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(itemIDs) {
+			return models.ErrChecklistOrder
+		}
+
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		for _, id := range itemIDs {
+			if !known[id] {
+				return models.ErrChecklistOrder
+			}
+			delete(known, id)
+		}
+
+		for position, id := range itemIDs {
+			if err := tx.Model(&models.ChecklistItem{}).
+				Where("id = ?", id).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return touchTask(tx, taskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTaskChecklist(taskID)
+}
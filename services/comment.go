@@ -2,34 +2,67 @@ package services
 
 import (
 	"taskive/models"
+	"taskive/storage"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type CommentService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	store storage.Storage
+	// editWindow is how long authors may edit their comments. After it
+	// only authors who own the project can.
+	editWindow time.Duration
 }
 
-func NewCommentService(db *gorm.DB) *CommentService {
-	return &CommentService{db: db}
+func NewCommentService(db *gorm.DB, store storage.Storage, editWindow time.Duration) *CommentService {
+	return &CommentService{db: db, store: store, editWindow: editWindow}
 }
 
 type CreateCommentInput struct {
 	Text string `json:"text" validate:"required"`
+	// ParentID makes the comment a reply.
+	ParentID *uint `json:"parent_id"`
+}
+
+type UpdateCommentInput struct {
+	Text string `json:"text" validate:"required"`
 }
 
 func (s *CommentService) Create(taskID, userID uint, input CreateCommentInput) (*models.Comment, error) {
+	if err := checkTaskWritable(s.db, taskID); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil {
+		if err := checkReplyParent(s.db, taskID, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
 	comment := &models.Comment{
-		TaskID: taskID,
-		UserID: userID,
-		Text:   input.Text,
+		TaskID:   taskID,
+		ParentID: input.ParentID,
+		UserID:   userID,
+		Text:     input.Text,
 	}
 
-	if err := s.db.Create(comment).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.Select("id, project_id").First(&task, taskID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return syncMentions(tx, &task, &comment.ID, userID, comment.Text)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.db.Preload("User").First(comment, comment.ID).Error; err != nil {
+	if err := s.db.Preload("User").Preload("Mentions.User").First(comment, comment.ID).Error; err != nil {
 		return nil, err
 	}
 
@@ -40,11 +73,126 @@ func (s *CommentService) GetTaskComments(taskID uint) ([]models.Comment, error)
 	var comments []models.Comment
 	err := s.db.Where("task_id = ?", taskID).
 		Preload("User").
+		Preload("Mentions.User").
 		Order("created_at DESC").
 		Find(&comments).Error
 	return comments, err
 }
 
+// Update replaces the text of the user's own comment and keeps the previous
+// text as a revision.
+func (s *CommentService) Update(commentID, userID uint, input UpdateCommentInput) (*models.Comment, error) {
+	var comment models.Comment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&comment, commentID).Error; err != nil {
+			return err
+		}
+		if comment.UserID != userID {
+			return models.ErrCommentNotAuthor
+		}
+
+		var task models.Task
+		if err := tx.Select("id, project_id").First(&task, comment.TaskID).Error; err != nil {
+			return err
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+		if s.editWindow > 0 && time.Since(comment.CreatedAt) > s.editWindow {
+			var owners int64
+			if err := tx.Model(&models.Member{}).
+				Where("project_id = ? AND user_id = ? AND role = ? AND status = ?",
+					task.ProjectID, userID, models.MemberRoleOwner, models.MemberStatusAccepted).
+				Count(&owners).Error; err != nil {
+				return err
+			}
+			if owners == 0 {
+				return models.ErrCommentEditWindow
+			}
+		}
+
+		if input.Text == comment.Text {
+			return nil
+		}
+		if err := tx.Create(&models.CommentRevision{
+			CommentID: comment.ID,
+			Text:      comment.Text,
+			EditorID:  userID,
+		}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		comment.Text = input.Text
+		comment.EditedAt = &now
+		if err := tx.Model(&comment).Updates(map[string]interface{}{
+			"text":      comment.Text,
+			"edited_at": comment.EditedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return syncMentions(tx, &task, &comment.ID, comment.UserID, comment.Text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("User").Preload("Mentions.User").First(&comment, comment.ID).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetRevisions returns the former texts of a comment, newest first, to
+// members of its project.
+func (s *CommentService) GetRevisions(commentID, userID uint) ([]models.CommentRevision, error) {
+	var comment models.Comment
+	if err := s.db.Select("id, task_id").First(&comment, commentID).Error; err != nil {
+		return nil, err
+	}
+	var task models.Task
+	if err := s.db.Select("id, project_id").First(&task, comment.TaskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkMember(s.db, task.ProjectID, userID); err != nil {
+		return nil, err
+	}
+
+	var revisions []models.CommentRevision
+	err := s.db.Where("comment_id = ?", commentID).
+		Preload("Editor").
+		Order("created_at DESC, id DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// Delete moves the comment to the trash.
 func (s *CommentService) Delete(commentID uint) error {
-	return s.db.Delete(&models.Comment{}, commentID).Error
-} 
\ No newline at end of file
+	var comment models.Comment
+	if err := s.db.Select("id, task_id").First(&comment, commentID).Error; err != nil {
+		return err
+	}
+	if err := checkTaskWritable(s.db, comment.TaskID); err != nil {
+		return err
+	}
+	return s.db.Delete(&comment).Error
+}
+
+// purgeComment permanently removes a comment and its attachments, and
+// returns the blobs to remove after commit. The comment must not have
+// replies left: they keep it in their thread as a deleted placeholder.
+func purgeComment(tx *gorm.DB, commentID uint) ([]string, error) {
+	keys, err := purgeAttachments(tx, "comment_id = ?", commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.CommentRevision{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Comment{}, commentID).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
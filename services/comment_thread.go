@@ -0,0 +1,165 @@
+package services
+
+import (
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxCommentDepth is how deep replies may nest; top-level comments are at
+// depth 1.
+const maxCommentDepth = 10
+
+// CommentNode is a comment within a thread. A comment that was deleted
+// while it had replies stays as a placeholder with Deleted set and no text
+// or author. Replies beyond the requested depth are left out; ReplyCount
+// still tells how many there are so clients can expand them.
+type CommentNode struct {
+	ID         uint             `json:"id"`
+	TaskID     uint             `json:"task_id"`
+	ParentID   *uint            `json:"parent_id"`
+	UserID     *uint            `json:"user_id"`
+	User       *models.User     `json:"user,omitempty"`
+	Text       string           `json:"text"`
+	Deleted    bool             `json:"deleted"`
+	EditedAt   *time.Time       `json:"edited_at"`
+	Mentions   []models.Mention `json:"mentions,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ReplyCount int              `json:"reply_count"`
+	Replies    []*CommentNode   `json:"replies,omitempty"`
+}
+
+// checkReplyParent makes sure a reply to parentID stays on the same task
+// and within maxCommentDepth.
+func checkReplyParent(tx *gorm.DB, taskID, parentID uint) error {
+	var parent models.Comment
+	if err := tx.Select("id, task_id, parent_id").First(&parent, parentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return models.ErrCommentParent
+		}
+		return err
+	}
+	if parent.TaskID != taskID {
+		return models.ErrCommentParent
+	}
+
+	depth := 2
+	for parent.ParentID != nil {
+		depth++
+		if depth > maxCommentDepth {
+			return models.ErrCommentDepth
+		}
+		if err := tx.Unscoped().Select("id, parent_id").First(&parent, *parent.ParentID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildCommentThreads arranges the comments of a task, trashed ones
+// included, into threads under rootID (nil for the whole task). Trashed
+// comments are kept only as placeholders for replies that are still
+// visible. depth limits the levels returned; 0 returns all of them.
+func buildCommentThreads(comments []models.Comment, rootID *uint, depth int) []*CommentNode {
+	children := map[uint][]*models.Comment{}
+	var roots []*models.Comment
+	for i := range comments {
+		comment := &comments[i]
+		switch {
+		case comment.ParentID == nil && rootID == nil:
+			roots = append(roots, comment)
+		case comment.ParentID != nil && rootID != nil && *comment.ParentID == *rootID:
+			roots = append(roots, comment)
+		}
+		if comment.ParentID != nil {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		}
+	}
+
+	var build func(comment *models.Comment, level int) *CommentNode
+	build = func(comment *models.Comment, level int) *CommentNode {
+		var replies []*CommentNode
+		for _, child := range children[comment.ID] {
+			if reply := build(child, level+1); reply != nil {
+				replies = append(replies, reply)
+			}
+		}
+		deleted := comment.DeletedAt.Valid
+		if deleted && len(replies) == 0 {
+			return nil
+		}
+
+		node := &CommentNode{
+			ID:         comment.ID,
+			TaskID:     comment.TaskID,
+			ParentID:   comment.ParentID,
+			Deleted:    deleted,
+			CreatedAt:  comment.CreatedAt,
+			ReplyCount: len(replies),
+		}
+		if !deleted {
+			user := comment.User
+			node.UserID = &comment.UserID
+			node.User = &user
+			node.Text = comment.Text
+			node.EditedAt = comment.EditedAt
+			node.Mentions = comment.Mentions
+		}
+		if depth == 0 || level < depth {
+			node.Replies = replies
+		}
+		return node
+	}
+
+	threads := []*CommentNode{}
+	for _, root := range roots {
+		if node := build(root, 1); node != nil {
+			threads = append(threads, node)
+		}
+	}
+	return threads
+}
+
+// loadThreadComments returns all of the task's comments, trashed ones
+// included, if the user is a member of the task's project.
+func (s *CommentService) loadThreadComments(taskID, userID uint) ([]models.Comment, error) {
+	var task models.Task
+	if err := s.db.Select("id, project_id").First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkMember(s.db, task.ProjectID, userID); err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	err := s.db.Unscoped().Where("task_id = ?", taskID).
+		Preload("User").
+		Preload("Mentions.User").
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error
+	return comments, err
+}
+
+// GetTaskThreads returns the task's comments as threads, oldest first.
+func (s *CommentService) GetTaskThreads(taskID, userID uint, depth int) ([]*CommentNode, error) {
+	comments, err := s.loadThreadComments(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentThreads(comments, nil, depth), nil
+}
+
+// GetReplies returns the replies to a comment as threads, for expanding a
+// collapsed part of a thread.
+func (s *CommentService) GetReplies(commentID, userID uint, depth int) ([]*CommentNode, error) {
+	var comment models.Comment
+	if err := s.db.Unscoped().Select("id, task_id").First(&comment, commentID).Error; err != nil {
+		return nil, err
+	}
+	comments, err := s.loadThreadComments(comment.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentThreads(comments, &comment.ID, depth), nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"taskive/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testComment builds a comment for thread tests, trashed or live.
+func testComment(id uint, parentID *uint, trashed bool) models.Comment {
+	comment := models.Comment{
+		ID:        id,
+		TaskID:    1,
+		ParentID:  parentID,
+		UserID:    7,
+		User:      models.User{ID: 7},
+		Text:      "comment " + strconv.Itoa(int(id)),
+		CreatedAt: time.Date(2026, 1, 1, 0, 0, int(id), 0, time.UTC),
+	}
+	if trashed {
+		comment.DeletedAt = gorm.DeletedAt{Time: comment.CreatedAt, Valid: true}
+	}
+	return comment
+}
+
+func parent(id uint) *uint {
+	return &id
+}
+
+// renderThreads writes threads as "id:replyCount{replies}", with a "*"
+// after the ID of deleted placeholders.
+func renderThreads(nodes []*CommentNode) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		part := strconv.FormatUint(uint64(node.ID), 10)
+		if node.Deleted {
+			part += "*"
+		}
+		part += ":" + strconv.Itoa(node.ReplyCount)
+		if len(node.Replies) > 0 {
+			part += "{" + renderThreads(node.Replies) + "}"
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestBuildCommentThreads(t *testing.T) {
+	// 1
+	// ├── 2
+	// │   └── 3
+	// └── 4
+	// 5
+	tree := []models.Comment{
+		testComment(1, nil, false),
+		testComment(2, parent(1), false),
+		testComment(3, parent(2), false),
+		testComment(4, parent(1), false),
+		testComment(5, nil, false),
+	}
+
+	tests := []struct {
+		name     string
+		comments []models.Comment
+		rootID   *uint
+		depth    int
+		want     string
+	}{
+		{
+			name:     "whole task",
+			comments: tree,
+			want:     "1:2{2:1{3:0} 4:0} 5:0",
+		},
+		{
+			name:     "depth 1 keeps reply counts",
+			comments: tree,
+			depth:    1,
+			want:     "1:2 5:0",
+		},
+		{
+			name:     "depth 2",
+			comments: tree,
+			depth:    2,
+			want:     "1:2{2:1 4:0} 5:0",
+		},
+		{
+			name:     "depth beyond the tree",
+			comments: tree,
+			depth:    maxCommentDepth,
+			want:     "1:2{2:1{3:0} 4:0} 5:0",
+		},
+		{
+			name:     "replies of a comment",
+			comments: tree,
+			rootID:   parent(1),
+			want:     "2:1{3:0} 4:0",
+		},
+		{
+			name:     "replies of a comment with depth",
+			comments: tree,
+			rootID:   parent(1),
+			depth:    1,
+			want:     "2:1 4:0",
+		},
+		{
+			name:     "replies of a leaf",
+			comments: tree,
+			rootID:   parent(3),
+			want:     "",
+		},
+		{
+			name: "trashed parent with a live reply",
+			comments: []models.Comment{
+				testComment(1, nil, true),
+				testComment(2, parent(1), false),
+			},
+			want: "1*:1{2:0}",
+		},
+		{
+			name: "trashed parent without replies",
+			comments: []models.Comment{
+				testComment(1, nil, true),
+				testComment(2, nil, false),
+			},
+			want: "2:0",
+		},
+		{
+			name: "trashed parent with only trashed replies",
+			comments: []models.Comment{
+				testComment(1, nil, true),
+				testComment(2, parent(1), true),
+				testComment(3, parent(2), true),
+			},
+			want: "",
+		},
+		{
+			name: "trashed reply in the middle of a thread",
+			comments: []models.Comment{
+				testComment(1, nil, false),
+				testComment(2, parent(1), true),
+				testComment(3, parent(2), false),
+				testComment(4, parent(1), true),
+			},
+			want: "1:1{2*:1{3:0}}",
+		},
+		{
+			name: "trashed placeholders kept for replies below the depth",
+			comments: []models.Comment{
+				testComment(1, nil, true),
+				testComment(2, parent(1), true),
+				testComment(3, parent(2), false),
+			},
+			depth: 1,
+			want:  "1*:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threads := buildCommentThreads(tt.comments, tt.rootID, tt.depth)
+			if threads == nil {
+				t.Fatal("buildCommentThreads returned nil, want an empty slice")
+			}
+			if got := renderThreads(threads); got != tt.want {
+				t.Errorf("threads = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommentThreadsPlaceholder(t *testing.T) {
+	comments := []models.Comment{
+		testComment(1, nil, true),
+		testComment(2, parent(1), false),
+	}
+
+	threads := buildCommentThreads(comments, nil, 0)
+	placeholder := threads[0]
+	if placeholder.Text != "" || placeholder.User != nil || placeholder.UserID != nil {
+		t.Errorf("placeholder has text %q, user %v and user ID %v, want none", placeholder.Text, placeholder.User, placeholder.UserID)
+	}
+	reply := placeholder.Replies[0]
+	if reply.Text != "comment 2" || reply.UserID == nil || *reply.UserID != 7 {
+		t.Errorf("reply has text %q and user ID %v, want the comment's", reply.Text, reply.UserID)
+	}
+}
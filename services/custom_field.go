@@ -0,0 +1,667 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CustomFieldService struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldService(db *gorm.DB) *CustomFieldService {
+	return &CustomFieldService{db: db}
+}
+
+const maxCustomTextLength = 1000
+
+type CreateCustomFieldInput struct {
+	Name     string                 `json:"name" validate:"required,max=100"`
+	Type     models.CustomFieldType `json:"type" validate:"required"`
+	Options  []string               `json:"options"`
+	Required bool                   `json:"required"`
+}
+
+type UpdateCustomFieldInput struct {
+	Name     string   `json:"name" validate:"omitempty,max=100"`
+	Options  []string `json:"options"`
+	Required *bool    `json:"required"`
+}
+
+// CustomFieldValues maps field IDs to raw JSON values; null clears a value.
+type CustomFieldValues map[string]json.RawMessage
+
+// CustomFieldCondition filters tasks on a custom field value. Op is one of
+// eq, gte or lte.
+type CustomFieldCondition struct {
+	FieldID uint
+	Op      string
+	Value   string
+}
+
+func validCustomFieldType(fieldType models.CustomFieldType) bool {
+	switch fieldType {
+	case models.CustomFieldText, models.CustomFieldNumber, models.CustomFieldDate,
+		models.CustomFieldSingleSelect, models.CustomFieldMultiSelect,
+		models.CustomFieldUser, models.CustomFieldURL, models.CustomFieldCheckbox:
+		return true
+	}
+	return false
+}
+
+// normalizeOptions trims the options and drops blanks and duplicates.
+func normalizeOptions(options []string) models.StringList {
+	result := models.StringList{}
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option != "" && !result.Contains(option) {
+			result = append(result, option)
+		}
+	}
+	return result
+}
+
+func customFieldNameTaken(db *gorm.DB, projectID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.CustomField{}).
+		Where("project_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", projectID, name, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func invalidCustomValue(field *models.CustomField, reason string) error {
+	return fmt.Errorf("%w: %s %s", models.ErrCustomFieldValue, field.Name, reason)
+}
+
+func parseCustomDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return truncateDay(t), nil
+}
+
+func validURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// parseCustomFieldValue validates raw against the field type and returns the
+// typed value, or nil when raw is null.
+func parseCustomFieldValue(db *gorm.DB, projectID uint, field *models.CustomField, raw json.RawMessage) (*models.CustomFieldValue, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	value := &models.CustomFieldValue{FieldID: field.ID}
+	switch field.Type {
+	case models.CustomFieldText, models.CustomFieldURL, models.CustomFieldSingleSelect:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, invalidCustomValue(field, "must be a string")
+		}
+		s = strings.TrimSpace(s)
+		switch {
+		case s == "":
+			return nil, nil
+		case len(s) > maxCustomTextLength:
+			return nil, invalidCustomValue(field, "is too long")
+		case field.Type == models.CustomFieldURL && !validURL(s):
+			return nil, invalidCustomValue(field, "must be an http(s) URL")
+		case field.Type == models.CustomFieldSingleSelect && !field.Options.Contains(s):
+			return nil, invalidCustomValue(field, "must be one of the field options")
+		}
+		value.TextValue = &s
+
+	case models.CustomFieldNumber:
+		var n float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, invalidCustomValue(field, "must be a number")
+		}
+		value.NumberValue = &n
+
+	case models.CustomFieldDate:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, invalidCustomValue(field, "must be a date string")
+		}
+		t, err := parseCustomDate(s)
+		if err != nil {
+			return nil, invalidCustomValue(field, "must be a date (YYYY-MM-DD)")
+		}
+		value.DateValue = &t
+
+	case models.CustomFieldMultiSelect:
+		var items []string
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, invalidCustomValue(field, "must be a list of strings")
+		}
+		list := normalizeOptions(items)
+		for _, item := range list {
+			if !field.Options.Contains(item) {
+				return nil, invalidCustomValue(field, "must only contain field options")
+			}
+		}
+		if len(list) == 0 {
+			return nil, nil
+		}
+		value.ListValue = list
+
+	case models.CustomFieldUser:
+		var id uint
+		if err := json.Unmarshal(raw, &id); err != nil || id == 0 {
+			return nil, invalidCustomValue(field, "must be a user ID")
+		}
+		var members int64
+		if err := db.Model(&models.Member{}).
+			Where("project_id = ? AND user_id = ? AND status = ?", projectID, id, models.MemberStatusAccepted).
+			Count(&members).Error; err != nil {
+			return nil, err
+		}
+		if members == 0 {
+			return nil, invalidCustomValue(field, "must be a project member")
+		}
+		value.UserValue = &id
+
+	case models.CustomFieldCheckbox:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, invalidCustomValue(field, "must be true or false")
+		}
+		value.BoolValue = &b
+	}
+	return value, nil
+}
+
+// customFieldDisplay returns the value as it appears in task JSON.
+func customFieldDisplay(field *models.CustomField, value *models.CustomFieldValue) any {
+	switch field.Type {
+	case models.CustomFieldNumber:
+		if value.NumberValue != nil {
+			return *value.NumberValue
+		}
+	case models.CustomFieldDate:
+		if value.DateValue != nil {
+			return value.DateValue.Format("2006-01-02")
+		}
+	case models.CustomFieldMultiSelect:
+		if value.ListValue != nil {
+			return value.ListValue
+		}
+	case models.CustomFieldUser:
+		if value.UserValue != nil {
+			return *value.UserValue
+		}
+	case models.CustomFieldCheckbox:
+		if value.BoolValue != nil {
+			return *value.BoolValue
+		}
+	default:
+		if value.TextValue != nil {
+			return *value.TextValue
+		}
+	}
+	return nil
+}
+
+// customFieldText renders a value for the activity log.
+func customFieldText(field *models.CustomField, value *models.CustomFieldValue) *string {
+	if value == nil {
+		return nil
+	}
+	switch v := customFieldDisplay(field, value).(type) {
+	case nil:
+		return nil
+	case string:
+		return stringValue(v)
+	default:
+		data, _ := json.Marshal(v)
+		return stringValue(string(data))
+	}
+}
+
+// setCustomFieldValues validates and stores the given values on the task and
+// logs every change. When creating, required fields must all be present.
+func setCustomFieldValues(tx *gorm.DB, task *models.Task, actorID uint, values CustomFieldValues, creating bool) error {
+	var fields []models.CustomField
+	if err := tx.Where("project_id = ?", task.ProjectID).Find(&fields).Error; err != nil {
+		return err
+	}
+	if len(fields) == 0 && len(values) == 0 {
+		return nil
+	}
+	byID := make(map[uint]*models.CustomField, len(fields))
+	for i := range fields {
+		byID[fields[i].ID] = &fields[i]
+	}
+
+	var existing []models.CustomFieldValue
+	if err := tx.Where("task_id = ?", task.ID).Find(&existing).Error; err != nil {
+		return err
+	}
+	current := make(map[uint]*models.CustomFieldValue, len(existing))
+	for i := range existing {
+		current[existing[i].FieldID] = &existing[i]
+	}
+
+	for key, raw := range values {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: unknown field %q", models.ErrCustomFieldValue, key)
+		}
+		field, ok := byID[uint(id)]
+		if !ok {
+			return fmt.Errorf("%w: unknown field %q", models.ErrCustomFieldValue, key)
+		}
+
+		value, err := parseCustomFieldValue(tx, task.ProjectID, field, raw)
+		if err != nil {
+			return err
+		}
+		old := current[field.ID]
+		if value == nil && field.Required {
+			return fmt.Errorf("%w: %s", models.ErrCustomFieldRequired, field.Name)
+		}
+
+		switch {
+		case value == nil && old != nil:
+			if err := tx.Delete(old).Error; err != nil {
+				return err
+			}
+		case value != nil:
+			value.TaskID = task.ID
+			if old != nil {
+				value.ID = old.ID
+			}
+			if err := tx.Save(value).Error; err != nil {
+				return err
+			}
+		}
+
+		oldText, newText := customFieldText(field, old), customFieldText(field, value)
+		if !creating && !sameValue(oldText, newText) {
+			if err := recordActivity(tx, task, actorID, models.TaskActivityUpdated, "custom_fields."+field.Name, oldText, newText); err != nil {
+				return err
+			}
+		}
+		if value == nil {
+			delete(current, field.ID)
+		} else {
+			current[field.ID] = value
+		}
+	}
+
+	if creating {
+		for _, field := range fields {
+			if _, ok := current[field.ID]; field.Required && !ok {
+				return fmt.Errorf("%w: %s", models.ErrCustomFieldRequired, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// attachCustomFieldValues fills Task.CustomFields, keyed by field ID, for
+// every task.
+func attachCustomFieldValues(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+		tasks[i].CustomFields = map[string]any{}
+	}
+
+	var values []models.CustomFieldValue
+	if err := db.Where("task_id IN ?", ids).Find(&values).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	fieldIDs := make([]uint, 0, len(values))
+	for _, value := range values {
+		fieldIDs = append(fieldIDs, value.FieldID)
+	}
+	var fields []models.CustomField
+	if err := db.Where("id IN ?", uniqueIDs(fieldIDs)).Find(&fields).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.CustomField, len(fields))
+	for i := range fields {
+		byID[fields[i].ID] = &fields[i]
+	}
+
+	byTask := make(map[uint]map[string]any, len(tasks))
+	for i := range tasks {
+		byTask[tasks[i].ID] = tasks[i].CustomFields
+	}
+	for i := range values {
+		field, ok := byID[values[i].FieldID]
+		if !ok {
+			continue
+		}
+		byTask[values[i].TaskID][strconv.FormatUint(uint64(field.ID), 10)] = customFieldDisplay(field, &values[i])
+	}
+	return nil
+}
+
+// customFieldColumn returns the value column used to compare values of the
+// field type.
+func customFieldColumn(fieldType models.CustomFieldType) string {
+	switch fieldType {
+	case models.CustomFieldNumber:
+		return "number_value"
+	case models.CustomFieldDate:
+		return "date_value"
+	case models.CustomFieldCheckbox:
+		return "bool_value"
+	case models.CustomFieldUser:
+		return "user_value"
+	case models.CustomFieldMultiSelect:
+		return "list_value"
+	}
+	return "text_value"
+}
+
+func loadProjectField(db *gorm.DB, projectID, fieldID uint) (*models.CustomField, error) {
+	var field models.CustomField
+	if err := db.Where("id = ? AND project_id = ?", fieldID, projectID).First(&field).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrCustomFieldNotFound
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+// applyCustomFieldFilter narrows query to tasks whose value matches the
+// condition. Ranges are only supported for numbers and dates; for
+// multi-select fields eq means "contains".
+func applyCustomFieldFilter(db, query *gorm.DB, projectID uint, cond CustomFieldCondition) (*gorm.DB, error) {
+	field, err := loadProjectField(db, projectID, cond.FieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	var operand interface{}
+	switch field.Type {
+	case models.CustomFieldNumber:
+		n, err := strconv.ParseFloat(cond.Value, 64)
+		if err != nil {
+			return nil, invalidCustomValue(field, "filter must be a number")
+		}
+		operand = n
+	case models.CustomFieldDate:
+		t, err := parseCustomDate(cond.Value)
+		if err != nil {
+			return nil, invalidCustomValue(field, "filter must be a date (YYYY-MM-DD)")
+		}
+		operand = t
+	case models.CustomFieldCheckbox:
+		b, err := strconv.ParseBool(cond.Value)
+		if err != nil {
+			return nil, invalidCustomValue(field, "filter must be true or false")
+		}
+		operand = b
+	case models.CustomFieldUser:
+		id, err := strconv.ParseUint(cond.Value, 10, 32)
+		if err != nil {
+			return nil, invalidCustomValue(field, "filter must be a user ID")
+		}
+		operand = uint(id)
+	case models.CustomFieldMultiSelect:
+		data, _ := json.Marshal([]string{cond.Value})
+		operand = string(data)
+	default:
+		operand = cond.Value
+	}
+
+	ranged := field.Type == models.CustomFieldNumber || field.Type == models.CustomFieldDate
+	var comparison string
+	switch {
+	case cond.Op == "eq" && field.Type == models.CustomFieldMultiSelect:
+		comparison = "list_value @> ?::jsonb"
+	case cond.Op == "eq":
+		comparison = customFieldColumn(field.Type) + " = ?"
+	case cond.Op == "gte" && ranged:
+		comparison = customFieldColumn(field.Type) + " >= ?"
+	case cond.Op == "lte" && ranged:
+		comparison = customFieldColumn(field.Type) + " <= ?"
+	default:
+		return nil, invalidCustomValue(field, "does not support "+cond.Op+" filters")
+	}
+
+	// An unset checkbox counts as unchecked.
+	if field.Type == models.CustomFieldCheckbox && operand == false {
+		checked := db.Model(&models.CustomFieldValue{}).Select("task_id").Where("field_id = ? AND bool_value", field.ID)
+		return query.Where("tasks.id NOT IN (?)", checked), nil
+	}
+
+	matching := db.Model(&models.CustomFieldValue{}).Select("task_id").Where("field_id = ?", field.ID).Where(comparison, operand)
+	return query.Where("tasks.id IN (?)", matching), nil
+}
+
+// customFieldSortColumn returns the value column to sort tasks by for the
+// project's field.
+func customFieldSortColumn(db *gorm.DB, projectID, fieldID uint) (string, error) {
+	field, err := loadProjectField(db, projectID, fieldID)
+	if err != nil {
+		return "", err
+	}
+	if field.Type == models.CustomFieldMultiSelect {
+		return "", models.ErrCustomFieldSort
+	}
+	return customFieldColumn(field.Type), nil
+}
+
+func (s *CustomFieldService) GetProjectFields(projectID uint) ([]models.CustomField, error) {
+	var fields []models.CustomField
+	err := s.db.Where("project_id = ?", projectID).
+		Order("position ASC, id ASC").
+		Find(&fields).Error
+	return fields, err
+}
+
+func (s *CustomFieldService) GetByID(fieldID uint) (*models.CustomField, error) {
+	var field models.CustomField
+	if err := s.db.First(&field, fieldID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrCustomFieldNotFound
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (s *CustomFieldService) Create(projectID uint, input CreateCustomFieldInput) (*models.CustomField, error) {
+	if !validCustomFieldType(input.Type) {
+		return nil, models.ErrCustomFieldType
+	}
+	if err := checkProjectWritable(s.db, projectID); err != nil {
+		return nil, err
+	}
+
+	field := &models.CustomField{
+		ProjectID: projectID,
+		Name:      strings.TrimSpace(input.Name),
+		Type:      input.Type,
+		Required:  input.Required,
+	}
+	if field.IsSelect() {
+		field.Options = normalizeOptions(input.Options)
+		if len(field.Options) == 0 {
+			return nil, models.ErrCustomFieldOptions
+		}
+	}
+
+	taken, err := customFieldNameTaken(s.db, projectID, field.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, models.ErrCustomFieldExists
+	}
+
+	var last struct{ Max *int }
+	if err := s.db.Model(&models.CustomField{}).
+		Select("MAX(position) AS max").
+		Where("project_id = ?", projectID).
+		Scan(&last).Error; err != nil {
+		return nil, err
+	}
+	if last.Max != nil {
+		field.Position = *last.Max + 1
+	}
+
+	if err := s.db.Create(field).Error; err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// Update renames the field, changes whether it is required or replaces its
+// options. Values using a removed option lose that option. The type cannot
+// be changed.
+func (s *CustomFieldService) Update(fieldID uint, input UpdateCustomFieldInput) (*models.CustomField, error) {
+	field, err := s.GetByID(fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(input.Name); name != "" {
+		taken, err := customFieldNameTaken(s.db, field.ProjectID, name, field.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, models.ErrCustomFieldExists
+		}
+		field.Name = name
+	}
+	if input.Required != nil {
+		field.Required = *input.Required
+	}
+
+	var removed []string
+	if input.Options != nil && field.IsSelect() {
+		options := normalizeOptions(input.Options)
+		if len(options) == 0 {
+			return nil, models.ErrCustomFieldOptions
+		}
+		for _, option := range field.Options {
+			if !options.Contains(option) {
+				removed = append(removed, option)
+			}
+		}
+		field.Options = options
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, field.ProjectID); err != nil {
+			return err
+		}
+		if err := tx.Save(field).Error; err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		if err := touchTasks(tx, "id IN (?)", fieldTasks(tx, field.ID)); err != nil {
+			return err
+		}
+		if field.Type == models.CustomFieldSingleSelect {
+			return tx.Where("field_id = ? AND text_value IN ?", field.ID, removed).
+				Delete(&models.CustomFieldValue{}).Error
+		}
+		for _, option := range removed {
+			if err := tx.Model(&models.CustomFieldValue{}).
+				Where("field_id = ?", field.ID).
+				Update("list_value", gorm.Expr("list_value - ?", option)).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("field_id = ? AND jsonb_array_length(list_value) = 0", field.ID).
+			Delete(&models.CustomFieldValue{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// fieldTasks selects the IDs of the tasks with a value for the field.
+func fieldTasks(tx *gorm.DB, fieldID uint) *gorm.DB {
+	return tx.Model(&models.CustomFieldValue{}).Select("task_id").Where("field_id = ?", fieldID)
+}
+
+// Delete removes the field together with every task's value for it.
+func (s *CustomFieldService) Delete(fieldID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var field models.CustomField
+		if err := tx.Select("id, project_id").First(&field, fieldID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrCustomFieldNotFound
+			}
+			return err
+		}
+		if err := checkProjectWritable(tx, field.ProjectID); err != nil {
+			return err
+		}
+		if err := touchTasks(tx, "id IN (?)", fieldTasks(tx, fieldID)); err != nil {
+			return err
+		}
+		if err := tx.Where("field_id = ?", fieldID).Delete(&models.CustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.CustomField{}, fieldID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrCustomFieldNotFound
+		}
+		return nil
+	})
+}
+
+// SetTaskValues updates some of the task's custom field values and returns
+// all of them.
+func (s *CustomFieldService) SetTaskValues(taskID, actorID uint, values CustomFieldValues) (map[string]any, error) {
+	var task models.Task
+	if err := s.db.First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := setCustomFieldValues(tx, &task, actorID, values, false); err != nil {
+			return err
+		}
+		return touchTask(tx, task.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{task}
+	if err := attachCustomFieldValues(s.db, tasks); err != nil {
+		return nil, err
+	}
+	return tasks[0].CustomFields, nil
+}
@@ -37,7 +37,7 @@ func (s *InvitationService) GetUserInvitations(userID uint) ([]InvitationRespons
 		Select("project_members.*, projects.name as project_name, users.name as inviter_name").
 		Joins("JOIN projects ON project_members.project_id = projects.id").
 		Joins("JOIN users ON projects.owner_id = users.id").
-		Where("project_members.user_id = ? AND project_members.status = ?", userID, "PENDING").
+		Where("project_members.user_id = ? AND project_members.status = ? AND projects.deleted_at IS NULL", userID, "PENDING").
 		Find(&invitations).Error
 
 	if err != nil {
@@ -61,9 +61,14 @@ func (s *InvitationService) GetUserInvitations(userID uint) ([]InvitationRespons
 	return response, nil
 }
 
-func (s *InvitationService) RespondToInvitation(userID, projectID uint, accept bool) error {
+func (s *InvitationService) RespondToInvitation(userID, projectID uint, accept bool, meta AuditMeta) error {
 	tx := s.db.Begin()
 
+	if err := checkProjectWritable(tx, projectID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	var member models.Member
 	if err := tx.Where("project_id = ? AND user_id = ? AND status = ?", 
 		projectID, userID, "PENDING").First(&member).Error; err != nil {
@@ -77,11 +82,19 @@ func (s *InvitationService) RespondToInvitation(userID, projectID uint, accept b
 			tx.Rollback()
 			return err
 		}
+		if err := recordAudit(tx, meta, models.AuditActionMemberAccept, models.AuditTargetMember, userID, &projectID, nil, member); err != nil {
+			tx.Rollback()
+			return err
+		}
 	} else {
 		if err := tx.Delete(&member).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
+		if err := recordAudit(tx, meta, models.AuditActionMemberReject, models.AuditTargetMember, userID, &projectID, member, nil); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	return tx.Commit().Error
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"strings"
+	"taskive/models"
+
+	"gorm.io/gorm"
+)
+
+type LabelService struct {
+	db *gorm.DB
+}
+
+func NewLabelService(db *gorm.DB) *LabelService {
+	return &LabelService{db: db}
+}
+
+type CreateLabelInput struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Color       string `json:"color" validate:"omitempty,hexcolor"`
+	Description string `json:"description"`
+}
+
+type UpdateLabelInput struct {
+	Name        string  `json:"name" validate:"omitempty,max=50"`
+	Color       string  `json:"color" validate:"omitempty,hexcolor"`
+	Description *string `json:"description"`
+}
+
+type MergeLabelsInput struct {
+	SourceID uint `json:"source_id" validate:"required"`
+	TargetID uint `json:"target_id" validate:"required"`
+}
+
+type SetTaskLabelsInput struct {
+	LabelIDs []uint `json:"label_ids"`
+}
+
+// labelName trims the label name and rejects names that are only whitespace.
+func labelName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", models.ErrLabelName
+	}
+	return name, nil
+}
+
+// labelNameTaken reports whether the project already has a label with the
+// name, ignoring case and the label being renamed.
+func labelNameTaken(db *gorm.DB, projectID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Label{}).
+		Where("project_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", projectID, name, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// findProjectLabels loads the labels by ID and makes sure all of them exist
+// and belong to the project.
+func findProjectLabels(db *gorm.DB, projectID uint, labelIDs []uint) ([]models.Label, error) {
+	if len(labelIDs) == 0 {
+		return []models.Label{}, nil
+	}
+
+	var labels []models.Label
+	if err := db.Where("id IN ?", labelIDs).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[uint]bool, len(labels))
+	for _, label := range labels {
+		if label.ProjectID != projectID {
+			return nil, models.ErrLabelProject
+		}
+		found[label.ID] = true
+	}
+	for _, id := range labelIDs {
+		if !found[id] {
+			return nil, models.ErrLabelNotFound
+		}
+	}
+	return labels, nil
+}
+
+func (s *LabelService) GetProjectLabels(projectID uint) ([]models.Label, error) {
+	var labels []models.Label
+	err := s.db.Where("project_id = ?", projectID).
+		Order("name ASC").
+		Find(&labels).Error
+	return labels, err
+}
+
+func (s *LabelService) GetByID(labelID uint) (*models.Label, error) {
+	var label models.Label
+	if err := s.db.First(&label, labelID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrLabelNotFound
+		}
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (s *LabelService) Create(projectID uint, input CreateLabelInput) (*models.Label, error) {
+	if err := checkProjectWritable(s.db, projectID); err != nil {
+		return nil, err
+	}
+	name, err := labelName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := labelNameTaken(s.db, projectID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, models.ErrLabelExists
+	}
+
+	label := &models.Label{
+		ProjectID:   projectID,
+		Name:        name,
+		Color:       strings.ToUpper(input.Color),
+		Description: input.Description,
+	}
+	if err := s.db.Create(label).Error; err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *LabelService) Update(labelID uint, input UpdateLabelInput) (*models.Label, error) {
+	label, err := s.GetByID(labelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != "" {
+		name, err := labelName(input.Name)
+		if err != nil {
+			return nil, err
+		}
+		taken, err := labelNameTaken(s.db, label.ProjectID, name, label.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, models.ErrLabelExists
+		}
+		label.Name = name
+	}
+	if input.Color != "" {
+		label.Color = strings.ToUpper(input.Color)
+	}
+	if input.Description != nil {
+		label.Description = *input.Description
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, label.ProjectID); err != nil {
+			return err
+		}
+		if err := tx.Save(label).Error; err != nil {
+			return err
+		}
+		return touchTasks(tx, "id IN (?)", labelTasks(tx, label.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// labelTasks selects the IDs of the tasks carrying the label.
+func labelTasks(tx *gorm.DB, labelID uint) *gorm.DB {
+	return tx.Table("task_labels").Select("task_id").Where("label_id = ?", labelID)
+}
+
+// Delete removes the label and takes it off every task that carried it.
+func (s *LabelService) Delete(labelID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var label models.Label
+		if err := tx.Select("id, project_id").First(&label, labelID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrLabelNotFound
+			}
+			return err
+		}
+		if err := checkProjectWritable(tx, label.ProjectID); err != nil {
+			return err
+		}
+		if err := touchTasks(tx, "id IN (?)", labelTasks(tx, labelID)); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", labelID).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Label{}, labelID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrLabelNotFound
+		}
+		return nil
+	})
+}
+
+// Merge moves every task from the source label to the target label and then
+// deletes the source. Tasks that already carry both keep a single target.
+func (s *LabelService) Merge(projectID uint, input MergeLabelsInput) (*models.Label, error) {
+	if input.SourceID == input.TargetID {
+		return nil, models.ErrLabelMerge
+	}
+
+	labels, err := findProjectLabels(s.db, projectID, []uint{input.SourceID, input.TargetID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, projectID); err != nil {
+			return err
+		}
+		if err := touchTasks(tx, "id IN (?)", labelTasks(tx, input.SourceID)); err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO task_labels (task_id, label_id)
+			SELECT task_id, ? FROM task_labels WHERE label_id = ?
+			ON CONFLICT DO NOTHING`, input.TargetID, input.SourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", input.SourceID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Label{}, input.SourceID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range labels {
+		if labels[i].ID == input.TargetID {
+			return &labels[i], nil
+		}
+	}
+	return nil, models.ErrLabelNotFound
+}
+
+// SetTaskLabels replaces the task's labels with the given ones.
+func (s *LabelService) SetTaskLabels(taskID uint, labelIDs []uint) ([]models.Label, error) {
+	var task models.Task
+	if err := s.db.First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+
+	labels, err := findProjectLabels(s.db, task.ProjectID, labelIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&task).Association("Labels").Replace(labels); err != nil {
+		return nil, err
+	}
+	if err := touchTask(s.db, task.ID); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (s *LabelService) AddTaskLabel(taskID, labelID uint) error {
+	var task models.Task
+	if err := s.db.First(&task, taskID).Error; err != nil {
+		return err
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return err
+	}
+
+	labels, err := findProjectLabels(s.db, task.ProjectID, []uint{labelID})
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(&task).Association("Labels").Append(labels); err != nil {
+		return err
+	}
+	return touchTask(s.db, task.ID)
+}
+
+func (s *LabelService) RemoveTaskLabel(taskID, labelID uint) error {
+	if err := checkTaskWritable(s.db, taskID); err != nil {
+		return err
+	}
+	if err := s.db.Exec("DELETE FROM task_labels WHERE task_id = ? AND label_id = ?", taskID, labelID).Error; err != nil {
+		return err
+	}
+	return touchTask(s.db, taskID)
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MentionService struct {
+	db *gorm.DB
+}
+
+func NewMentionService(db *gorm.DB) *MentionService {
+	return &MentionService{db: db}
+}
+
+const (
+	defaultMentionPageSize = 50
+	maxMentionPageSize     = 200
+)
+
+// mentionPattern matches @handle where handle is a full email address or
+// the part of one before the @. The @ must not follow a word character, so
+// plain email addresses in the text are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([\w.%+-]+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+// parseMentionHandles returns the lower-cased handles mentioned in text,
+// without the leading @ and in order of first appearance.
+func parseMentionHandles(text string) []string {
+	var handles []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// A handle at the end of a sentence keeps its period otherwise.
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if handle != "" && !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// mentionMember is a project member that handles are matched against.
+type mentionMember struct {
+	UserID uint
+	Email  string
+}
+
+// resolveMentions maps handles to accepted members of the project.
+func resolveMentions(tx *gorm.DB, projectID uint, handles []string) (map[string]uint, error) {
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	var members []mentionMember
+	if err := tx.Table("project_members").
+		Select("project_members.user_id, users.email").
+		Joins("JOIN users ON users.id = project_members.user_id").
+		Where("project_members.project_id = ? AND project_members.status = ?", projectID, models.MemberStatusAccepted).
+		Scan(&members).Error; err != nil {
+		return nil, err
+	}
+	return matchMentionHandles(members, handles), nil
+}
+
+// matchMentionHandles maps handles to members. A handle is a member's email
+// or its local part; local parts shared by several members are ambiguous
+// and ignored.
+func matchMentionHandles(members []mentionMember, handles []string) map[string]uint {
+	byEmail := make(map[string]uint, len(members))
+	byName := make(map[string]uint, len(members))
+	ambiguous := map[string]bool{}
+	for _, member := range members {
+		email := strings.ToLower(member.Email)
+		byEmail[email] = member.UserID
+		name := email
+		if at := strings.IndexByte(email, '@'); at >= 0 {
+			name = email[:at]
+		}
+		if _, ok := byName[name]; ok {
+			ambiguous[name] = true
+		}
+		byName[name] = member.UserID
+	}
+
+	resolved := map[string]uint{}
+	for _, handle := range handles {
+		if id, ok := byEmail[handle]; ok {
+			resolved[handle] = id
+		} else if id, ok := byName[handle]; ok && !ambiguous[handle] {
+			resolved[handle] = id
+		}
+	}
+	return resolved
+}
+
+// mentionTargets returns the users to notify with the handle that first
+// mentioned each of them, leaving out the author.
+func mentionTargets(handles []string, resolved map[string]uint, authorID uint) map[uint]string {
+	targets := map[uint]string{}
+	for _, handle := range handles {
+		if id, ok := resolved[handle]; ok && id != authorID {
+			if _, dup := targets[id]; !dup {
+				targets[id] = "@" + handle
+			}
+		}
+	}
+	return targets
+}
+
+// syncMentions makes the stored mentions of a comment, or of the task
+// description when commentID is nil, match text. Mentions that remain keep
+// their read state; new ones show up unread in the mentioned user's feed.
+// Authors mentioning themselves are skipped.
+func syncMentions(tx *gorm.DB, task *models.Task, commentID *uint, authorID uint, text string) error {
+	handles := parseMentionHandles(text)
+	resolved, err := resolveMentions(tx, task.ProjectID, handles)
+	if err != nil {
+		return err
+	}
+	wanted := mentionTargets(handles, resolved, authorID)
+
+	scope := tx.Where("task_id = ?", task.ID)
+	if commentID != nil {
+		scope = scope.Where("comment_id = ?", *commentID)
+	} else {
+		scope = scope.Where("comment_id IS NULL")
+	}
+	var existing []models.Mention
+	if err := scope.Find(&existing).Error; err != nil {
+		return err
+	}
+
+	var stale []uint
+	for _, mention := range existing {
+		if _, ok := wanted[mention.UserID]; ok {
+			delete(wanted, mention.UserID)
+		} else {
+			stale = append(stale, mention.ID)
+		}
+	}
+	if len(stale) > 0 {
+		if err := tx.Delete(&models.Mention{}, stale).Error; err != nil {
+			return err
+		}
+	}
+	for userID, handle := range wanted {
+		if err := tx.Create(&models.Mention{
+			UserID:    userID,
+			ProjectID: task.ProjectID,
+			TaskID:    task.ID,
+			CommentID: commentID,
+			AuthorID:  authorID,
+			Handle:    handle,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserMentions returns the mentions of the user, newest first. Mentions
+// in trashed tasks or comments are left out, as are those in projects the
+// user is no longer a member of.
+func (s *MentionService) GetUserMentions(userID uint, unreadOnly bool, limit, offset int) ([]models.Mention, error) {
+	switch {
+	case limit < 1:
+		limit = defaultMentionPageSize
+	case limit > maxMentionPageSize:
+		limit = maxMentionPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := s.db.Model(&models.Mention{}).
+		Joins("JOIN project_members ON project_members.project_id = mentions.project_id AND project_members.user_id = mentions.user_id AND project_members.status = ?", models.MemberStatusAccepted).
+		Joins("JOIN tasks ON tasks.id = mentions.task_id AND tasks.deleted_at IS NULL").
+		Joins("LEFT JOIN comments ON comments.id = mentions.comment_id").
+		Where("mentions.user_id = ? AND comments.deleted_at IS NULL", userID)
+	if unreadOnly {
+		query = query.Where("mentions.read_at IS NULL")
+	}
+
+	mentions := []models.Mention{}
+	err := query.
+		Preload("User").
+		Preload("Author").
+		Preload("Task").
+		Preload("Comment").
+		Order("mentions.created_at DESC, mentions.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&mentions).Error
+	return mentions, err
+}
+
+// MarkRead marks one of the user's mentions as read.
+func (s *MentionService) MarkRead(mentionID, userID uint) error {
+	result := s.db.Model(&models.Mention{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", mentionID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := s.db.Model(&models.Mention{}).
+			Where("id = ? AND user_id = ?", mentionID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return models.ErrMentionNotFound
+		}
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread mention of the user as read.
+func (s *MentionService) MarkAllRead(userID uint) error {
+	return s.db.Model(&models.Mention{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentionHandles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"local part", "ping @alice", []string{"alice"}},
+		{"start of text", "@alice please look", []string{"alice"}},
+		{"trailing period", "Thanks @alice.", []string{"alice"}},
+		{"dotted local part with trailing period", "ask @bob.smith.", []string{"bob.smith"}},
+		{"full email with trailing period", "cc @alice@example.com.", []string{"alice@example.com"}},
+		{"punctuation around the handle", "(@carol), @dave!", []string{"carol", "dave"}},
+		{"after a line break", "first line\n@erin", []string{"erin"}},
+		{"lower-cased and deduplicated", "@Alice and @ALICE and @alice", []string{"alice"}},
+		{"order of first appearance", "@bob then @alice then @bob", []string{"bob", "alice"}},
+		{"plain email address", "mail alice@example.com", nil},
+		{"after a period", "see .@alice", nil},
+		{"double at", "@@alice", nil},
+		{"only periods", "@... nothing", nil},
+		{"no handle", "email me @ home", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMentionHandles(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMentionHandles(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMentionHandles(t *testing.T) {
+	members := []mentionMember{
+		{UserID: 1, Email: "alice@example.com"},
+		{UserID: 2, Email: "alice@other.org"},
+		{UserID: 3, Email: "bob@example.com"},
+		{UserID: 4, Email: "Carol@Example.com"},
+	}
+
+	tests := []struct {
+		handle string
+		want   uint
+	}{
+		{"bob", 3},
+		{"bob@example.com", 3},
+		{"carol", 4},
+		{"carol@example.com", 4},
+		// Shared local parts are ambiguous, full emails are not.
+		{"alice", 0},
+		{"alice@example.com", 1},
+		{"alice@other.org", 2},
+		{"dave", 0},
+		{"bob@other.org", 0},
+	}
+
+	handles := make([]string, len(tests))
+	for i, tt := range tests {
+		handles[i] = tt.handle
+	}
+	resolved := matchMentionHandles(members, handles)
+	for _, tt := range tests {
+		if got := resolved[tt.handle]; got != tt.want {
+			t.Errorf("handle %q resolved to user %d, want %d", tt.handle, got, tt.want)
+		}
+	}
+}
+
+func TestMentionTargets(t *testing.T) {
+	resolved := map[string]uint{
+		"alice":           1,
+		"alice@other.org": 1,
+		"bob":             2,
+	}
+
+	tests := []struct {
+		name     string
+		handles  []string
+		authorID uint
+		want     map[uint]string
+	}{
+		{
+			name:    "first handle of each user wins",
+			handles: []string{"alice@other.org", "alice", "bob"},
+			want:    map[uint]string{1: "@alice@other.org", 2: "@bob"},
+		},
+		{
+			name:     "self-mention is skipped",
+			handles:  []string{"alice", "bob"},
+			authorID: 1,
+			want:     map[uint]string{2: "@bob"},
+		},
+		{
+			name:     "only a self-mention",
+			handles:  []string{"bob"},
+			authorID: 2,
+			want:     map[uint]string{},
+		},
+		{
+			name:    "unresolved handles are ignored",
+			handles: []string{"carol", "bob"},
+			want:    map[uint]string{2: "@bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mentionTargets(tt.handles, resolved, tt.authorID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mentionTargets(%s) = %v, want %v", strings.Join(tt.handles, ", "), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MergePatch is an RFC 7396 JSON merge patch: fields that are absent stay
+// as they are and an explicit null clears the field.
+type MergePatch map[string]json.RawMessage
+
+// fields returns the patched field names in a stable order, so that the
+// first invalid field reported does not depend on map iteration.
+func (p MergePatch) fields() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func invalidPatch(field, reason string) error {
+	return fmt.Errorf("%w: %s %s", models.ErrInvalidPatch, field, reason)
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// patchString decodes a string field. Required fields may neither be null
+// nor blank; optional ones read null as the empty string.
+func patchString(field string, raw json.RawMessage, required bool) (string, error) {
+	if isNull(raw) {
+		if required {
+			return "", invalidPatch(field, "cannot be cleared")
+		}
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", invalidPatch(field, "must be a string")
+	}
+	if required && strings.TrimSpace(s) == "" {
+		return "", invalidPatch(field, "cannot be empty")
+	}
+	return s, nil
+}
+
+// patchTime decodes a date (YYYY-MM-DD) or RFC 3339 timestamp; null gives
+// the zero time.
+func patchTime(field string, raw json.RawMessage) (time.Time, error) {
+	if isNull(raw) {
+		return time.Time{}, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}, invalidPatch(field, "must be a date string")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, invalidPatch(field, "must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+// patchID decodes a reference to another record; null gives nil.
+func patchID(field string, raw json.RawMessage) (*uint, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	var id uint
+	if err := json.Unmarshal(raw, &id); err != nil || id == 0 {
+		return nil, invalidPatch(field, "must be an ID or null")
+	}
+	return &id, nil
+}
+
+// clearedCustomFields returns the values clearing every custom field the
+// task has a value for, which is what a null custom_fields patch means.
+func clearedCustomFields(db *gorm.DB, taskID uint) (CustomFieldValues, error) {
+	var fieldIDs []uint
+	if err := db.Model(&models.CustomFieldValue{}).Where("task_id = ?", taskID).
+		Pluck("field_id", &fieldIDs).Error; err != nil {
+		return nil, err
+	}
+	values := make(CustomFieldValues, len(fieldIDs))
+	for _, id := range fieldIDs {
+		values[strconv.FormatUint(uint64(id), 10)] = json.RawMessage("null")
+	}
+	return values, nil
+}
+
+// Patch applies a merge patch to the task. A non-zero version makes it
+// conditional like Update.
+func (s *TaskService) Patch(taskID, actorID uint, patch MergePatch, version int) (*models.Task, error) {
+	var result *models.Task
+	err := retryUnconditional(version, func() (err error) {
+		result, err = s.patch(taskID, actorID, patch, version)
+		return err
+	})
+	return result, err
+}
+
+func (s *TaskService) patch(taskID, actorID uint, patch MergePatch, version int) (*models.Task, error) {
+	var task models.Task
+	if err := s.db.First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if version != 0 && task.Version != version {
+		return nil, models.ErrVersionConflict
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+	before := task
+
+	var customFields CustomFieldValues
+	for _, field := range patch.fields() {
+		raw := patch[field]
+		var err error
+		switch field {
+		case "title":
+			task.Title, err = patchString(field, raw, true)
+		case "description":
+			task.Description, err = patchString(field, raw, false)
+		case "status":
+			var status string
+			if status, err = patchString(field, raw, true); err == nil {
+				switch models.TaskStatus(status) {
+				case models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusDone:
+					task.Status = models.TaskStatus(status)
+				default:
+					err = invalidPatch(field, "must be TODO, IN_PROGRESS or DONE")
+				}
+			}
+		case "priority":
+			var priority string
+			if priority, err = patchString(field, raw, true); err == nil {
+				switch models.TaskPriority(priority) {
+				case models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh:
+					task.Priority = models.TaskPriority(priority)
+				default:
+					err = invalidPatch(field, "must be LOW, MEDIUM or HIGH")
+				}
+			}
+		case "due_date":
+			task.DueDate, err = patchTime(field, raw)
+		case "assignee_id":
+			if task.AssigneeID, err = patchID(field, raw); err == nil && task.AssigneeID != nil {
+				var member bool
+				if member, err = isProjectMember(s.db, task.ProjectID, *task.AssigneeID); err == nil && !member {
+					err = invalidPatch(field, "must be a project member")
+				}
+			}
+		case "sprint_id":
+			if task.SprintID, err = patchID(field, raw); err == nil && task.SprintID != nil {
+				err = checkSprintAssignable(s.db, task.ProjectID, *task.SprintID)
+			}
+		case "story_points":
+			task.StoryPoints = nil
+			if !isNull(raw) {
+				var points int
+				if json.Unmarshal(raw, &points) != nil || points < 0 {
+					err = invalidPatch(field, "must be a non-negative integer or null")
+				}
+				task.StoryPoints = &points
+			}
+		case "custom_fields":
+			if isNull(raw) {
+				customFields, err = clearedCustomFields(s.db, task.ID)
+			} else if json.Unmarshal(raw, &customFields) != nil {
+				err = invalidPatch(field, "must be an object")
+			}
+		default:
+			err = invalidPatch(field, "cannot be patched")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	task.Version++
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &task, before.Version); err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, &task, before.Status, task.Status); err != nil {
+			return err
+		}
+		if err := recordTaskChanges(tx, actorID, &before, &task); err != nil {
+			return err
+		}
+		if task.Description != before.Description {
+			if err := syncMentions(tx, &task, nil, actorID, task.Description); err != nil {
+				return err
+			}
+		}
+		if len(customFields) == 0 {
+			return nil
+		}
+		return setCustomFieldValues(tx, &task, actorID, customFields, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(task.ID)
+}
+
+// Patch applies a merge patch to the project. A non-zero version makes it
+// conditional like Update.
+func (s *ProjectService) Patch(projectID uint, patch MergePatch, version int) (*models.Project, error) {
+	var result *models.Project
+	err := retryUnconditional(version, func() (err error) {
+		result, err = s.patch(projectID, patch, version)
+		return err
+	})
+	return result, err
+}
+
+func (s *ProjectService) patch(projectID uint, patch MergePatch, version int) (*models.Project, error) {
+	var project models.Project
+	if err := s.db.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	if version != 0 && project.Version != version {
+		return nil, models.ErrVersionConflict
+	}
+	if project.ArchivedAt != nil {
+		return nil, models.ErrProjectArchived
+	}
+
+	var key string
+	for _, field := range patch.fields() {
+		raw := patch[field]
+		var err error
+		switch field {
+		case "name":
+			project.Name, err = patchString(field, raw, true)
+		case "key":
+			key, err = patchString(field, raw, true)
+		case "description":
+			project.Description, err = patchString(field, raw, false)
+		case "start_date":
+			if project.StartDate, err = patchTime(field, raw); err == nil && project.StartDate.IsZero() {
+				err = invalidPatch(field, "cannot be cleared")
+			}
+		case "end_date":
+			project.EndDate, err = patchTime(field, raw)
+		default:
+			err = invalidPatch(field, "cannot be patched")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !project.EndDate.IsZero() && project.EndDate.Before(project.StartDate) {
+		return nil, invalidPatch("end_date", "must not be before start_date")
+	}
+	read := project.Version
+	project.Version++
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if key != "" && !strings.EqualFold(key, project.Key) {
+			resolved, err := resolveProjectKey(tx, key, project.Name)
+			if err != nil {
+				return err
+			}
+			if err := changeProjectKey(tx, &project, resolved); err != nil {
+				return err
+			}
+		}
+		return saveVersioned(tx, &project, read, "Sequence")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
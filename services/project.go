@@ -2,22 +2,27 @@ package services
 
 import (
 	"fmt"
+	"strings"
 	"taskive/models"
+	"taskive/storage"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProjectService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	store storage.Storage
 }
 
-func NewProjectService(db *gorm.DB) *ProjectService {
-	return &ProjectService{db: db}
+func NewProjectService(db *gorm.DB, store storage.Storage) *ProjectService {
+	return &ProjectService{db: db, store: store}
 }
 
 type CreateProjectInput struct {
 	Name        string    `json:"name" validate:"required"`
+	Key         string    `json:"key"`
 	Description string    `json:"description"`
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
@@ -25,23 +30,43 @@ type CreateProjectInput struct {
 
 type UpdateProjectInput struct {
 	Name        string    `json:"name"`
+	Key         string    `json:"key"`
 	Description string    `json:"description"`
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 }
 
 func (s *ProjectService) Create(userID uint, input CreateProjectInput) (*models.Project, error) {
+	var project *models.Project
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		project, err = createProject(tx, userID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// createProject creates the project with userID as its accepted owner. The
+// key is generated from the name unless one is given.
+func createProject(tx *gorm.DB, userID uint, input CreateProjectInput) (*models.Project, error) {
+	key, err := resolveProjectKey(tx, input.Key, input.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	project := &models.Project{
 		Name:        input.Name,
+		Key:         key,
 		Description: input.Description,
 		StartDate:   input.StartDate,
 		EndDate:     input.EndDate,
 		OwnerID:     userID,
 	}
-
-	tx := s.db.Begin()
 	if err := tx.Create(project).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
@@ -51,24 +76,37 @@ func (s *ProjectService) Create(userID uint, input CreateProjectInput) (*models.
 		Role:      models.MemberRoleOwner,
 		Status:    models.MemberStatusAccepted, // Owner langsung accepted
 	}
-
 	if err := tx.Create(member).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	return project, nil
 }
 
-func (s *ProjectService) Update(projectID uint, input UpdateProjectInput) (*models.Project, error) {
+// Update changes the project. A non-zero version makes the update
+// conditional: it fails with ErrVersionConflict once the project has
+// changed since the caller read that version. Without one, an update that
+// races another write is redone on the fresh project.
+func (s *ProjectService) Update(projectID uint, input UpdateProjectInput, version int) (*models.Project, error) {
+	var result *models.Project
+	err := retryUnconditional(version, func() (err error) {
+		result, err = s.update(projectID, input, version)
+		return err
+	})
+	return result, err
+}
+
+func (s *ProjectService) update(projectID uint, input UpdateProjectInput, version int) (*models.Project, error) {
 	var project models.Project
 	if err := s.db.First(&project, projectID).Error; err != nil {
 		return nil, err
 	}
+	if version != 0 && project.Version != version {
+		return nil, models.ErrVersionConflict
+	}
+	if project.ArchivedAt != nil {
+		return nil, models.ErrProjectArchived
+	}
 
 	if input.Name != "" {
 		project.Name = input.Name
@@ -81,36 +119,178 @@ func (s *ProjectService) Update(projectID uint, input UpdateProjectInput) (*mode
 		project.EndDate = input.EndDate
 	}
 
-	if err := s.db.Save(&project).Error; err != nil {
+	read := project.Version
+	project.Version++
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if input.Key != "" && !strings.EqualFold(input.Key, project.Key) {
+			key, err := resolveProjectKey(tx, input.Key, project.Name)
+			if err != nil {
+				return err
+			}
+			if err := changeProjectKey(tx, &project, key); err != nil {
+				return err
+			}
+		}
+		// The task sequence is only ever changed by task inserts.
+		return saveVersioned(tx, &project, read, "Sequence")
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &project, nil
 }
 
-func (s *ProjectService) Delete(projectID uint) error {
+// Delete moves the project to the trash together with its live tasks and
+// their comments. They share one deletion time so a restore brings back
+// exactly what was trashed here.
+func (s *ProjectService) Delete(projectID uint, meta AuditMeta) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("project_id = ?", projectID).Delete(&models.Member{}).Error; err != nil {
+		var project models.Project
+		if err := tx.Preload("Members").First(&project, projectID).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, meta, models.AuditActionProjectDelete, models.AuditTargetProject, project.ID, &project.ID, project, nil); err != nil {
 			return err
 		}
-		if err := tx.Where("project_id = ?", projectID).Delete(&models.Task{}).Error; err != nil {
+
+		now := time.Now().Truncate(time.Microsecond)
+		projectTasks := tx.Unscoped().Model(&models.Task{}).Select("id").Where("project_id = ?", projectID)
+		if err := tx.Model(&models.Comment{}).Where("task_id IN (?)", projectTasks).
+			UpdateColumn("deleted_at", now).Error; err != nil {
 			return err
 		}
-		if err := tx.Delete(&models.Project{}, projectID).Error; err != nil {
+		if err := tx.Model(&models.Task{}).Where("project_id = ?", projectID).
+			UpdateColumn("deleted_at", now).Error; err != nil {
 			return err
 		}
-		return nil
+		return tx.Model(&project).UpdateColumn("deleted_at", now).Error
 	})
 }
 
-func (s *ProjectService) GetUserProjects(userID uint) ([]models.Project, error) {
+// purgeProject permanently removes a project and everything in it, trashed
+// or not, and returns the attachment blobs to remove after commit.
+func purgeProject(tx *gorm.DB, projectID uint) ([]string, error) {
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.Member{}).Error; err != nil {
+		return nil, err
+	}
+	projectTasks := tx.Unscoped().Model(&models.Task{}).Select("id").Where("project_id = ?", projectID)
+	projectComments := tx.Unscoped().Model(&models.Comment{}).Select("id").Where("task_id IN (?)", projectTasks)
+	if err := tx.Where("comment_id IN (?)", projectComments).Delete(&models.CommentRevision{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("task_id IN (?)", projectTasks).Delete(&models.Comment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN (?)", projectTasks).Delete(&models.ChecklistItem{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_labels WHERE task_id IN (?)", projectTasks).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.Label{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN (?)", projectTasks).Delete(&models.CustomFieldValue{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN (?)", projectTasks).Delete(&models.TaskKeyAlias{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.CustomField{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.SavedViewDefault{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.SavedView{}).Error; err != nil {
+		return nil, err
+	}
+	keys, err := purgeAttachments(tx, "project_id = ?", projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("project_id = ?", projectID).Delete(&models.Task{}).Error; err != nil {
+		return nil, err
+	}
+	projectSprints := tx.Model(&models.Sprint{}).Select("id").Where("project_id = ?", projectID)
+	if err := tx.Where("sprint_id IN (?)", projectSprints).Delete(&models.SprintTask{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.Sprint{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.TaskStatusChange{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.TaskActivity{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Project{}, projectID).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetUserProjects lists the user's projects. Archived projects are left out
+// unless includeArchived is set.
+func (s *ProjectService) GetUserProjects(userID uint, includeArchived bool) ([]models.Project, error) {
 	var projects []models.Project
-	err := s.db.Joins("JOIN project_members ON projects.id = project_members.project_id").
-		Where("project_members.user_id = ? AND project_members.status = ?", userID, models.MemberStatusAccepted).
-		Find(&projects).Error
+	query := s.db.Joins("JOIN project_members ON projects.id = project_members.project_id").
+		Where("project_members.user_id = ? AND project_members.status = ?", userID, models.MemberStatusAccepted)
+	if !includeArchived {
+		query = query.Where("projects.archived_at IS NULL")
+	}
+	err := query.Find(&projects).Error
 	return projects, err
 }
 
+// Archive makes the project read-only and hides it from default listings.
+func (s *ProjectService) Archive(projectID uint, meta AuditMeta) (*models.Project, error) {
+	return s.setArchived(projectID, true, meta)
+}
+
+func (s *ProjectService) Unarchive(projectID uint, meta AuditMeta) (*models.Project, error) {
+	return s.setArchived(projectID, false, meta)
+}
+
+func (s *ProjectService) setArchived(projectID uint, archived bool, meta AuditMeta) (*models.Project, error) {
+	var project models.Project
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&project, projectID).Error; err != nil {
+			return err
+		}
+		if (project.ArchivedAt != nil) == archived {
+			return nil
+		}
+		before := project
+
+		action := models.AuditActionProjectUnarchive
+		project.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			action = models.AuditActionProjectArchive
+			project.ArchivedAt = &now
+		}
+		project.Version++
+		if err := tx.Model(&project).Updates(map[string]interface{}{
+			"archived_at": project.ArchivedAt,
+			"version":     project.Version,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, meta, action, models.AuditTargetProject, project.ID, &project.ID, before, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
 func (s *ProjectService) GetByID(projectID uint) (*models.Project, error) {
 	var project models.Project
 	if err := s.db.First(&project, projectID).Error; err != nil {
@@ -119,14 +299,134 @@ func (s *ProjectService) GetByID(projectID uint) (*models.Project, error) {
 	return &project, nil
 }
 
-func (s *ProjectService) AddMember(projectID, userID uint, role models.MemberRole) error {
+func (s *ProjectService) AddMember(projectID, userID uint, role models.MemberRole, meta AuditMeta) error {
 	member := &models.Member{
 		ProjectID: projectID,
 		UserID:    userID,
 		Role:      role,
 		Status:    models.MemberStatusPending, // Member baru status pending
 	}
-	return s.db.Create(member).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, projectID); err != nil {
+			return err
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, meta, models.AuditActionMemberInvite, models.AuditTargetMember, userID, &projectID, nil, member)
+	})
+}
+
+func (s *ProjectService) UpdateMemberRole(projectID, userID uint, role models.MemberRole, meta AuditMeta) (*models.Member, error) {
+	var member models.Member
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, projectID); err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrMemberNotFound
+			}
+			return err
+		}
+		before := member
+		if before.Role == role {
+			return nil
+		}
+		if before.Role == models.MemberRoleOwner {
+			if err := checkRemainingOwner(tx, projectID, userID); err != nil {
+				return err
+			}
+		}
+
+		member.Role = role
+		if err := tx.Model(&member).Update("role", role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, meta, models.AuditActionMemberRole, models.AuditTargetMember, userID, &projectID, before, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *ProjectService) RemoveMember(projectID, userID uint, meta AuditMeta) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, projectID); err != nil {
+			return err
+		}
+		var member models.Member
+		if err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrMemberNotFound
+			}
+			return err
+		}
+		if member.Role == models.MemberRoleOwner {
+			if err := checkRemainingOwner(tx, projectID, userID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.Member{}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, meta, models.AuditActionMemberRemove, models.AuditTargetMember, userID, &projectID, member, nil)
+	})
+}
+
+// checkProjectWritable returns ErrProjectArchived when the project is
+// archived, which makes it read-only.
+func checkProjectWritable(db *gorm.DB, projectID uint) error {
+	var archived int64
+	if err := db.Model(&models.Project{}).
+		Where("id = ? AND archived_at IS NOT NULL", projectID).
+		Count(&archived).Error; err != nil {
+		return err
+	}
+	if archived > 0 {
+		return models.ErrProjectArchived
+	}
+	return nil
+}
+
+// checkTaskWritable is checkProjectWritable for the project of the task.
+func checkTaskWritable(db *gorm.DB, taskID uint) error {
+	var archived int64
+	if err := db.Model(&models.Project{}).
+		Where("id = (?) AND archived_at IS NOT NULL", db.Unscoped().Model(&models.Task{}).Select("project_id").Where("id = ?", taskID)).
+		Count(&archived).Error; err != nil {
+		return err
+	}
+	if archived > 0 {
+		return models.ErrProjectArchived
+	}
+	return nil
+}
+
+// isProjectMember reports whether the user has accepted membership of the project.
+func isProjectMember(db *gorm.DB, projectID, userID uint) (bool, error) {
+	var members int64
+	err := db.Model(&models.Member{}).
+		Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, models.MemberStatusAccepted).
+		Count(&members).Error
+	return members > 0, err
+}
+
+// checkRemainingOwner fails when userID is the project's only accepted owner.
+func checkRemainingOwner(tx *gorm.DB, projectID, userID uint) error {
+	var owners int64
+	if err := tx.Model(&models.Member{}).
+		Where("project_id = ? AND user_id <> ? AND role = ? AND status = ?",
+			projectID, userID, models.MemberRoleOwner, models.MemberStatusAccepted).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return models.ErrLastOwner
+	}
+	return nil
 }
 
 type ProjectInvitation struct {
@@ -140,7 +440,6 @@ type ProjectInvitation struct {
 func (s *ProjectService) GetUserInvitations(userID uint) ([]ProjectInvitation, error) {
 	var invitations []ProjectInvitation
 
-	// Debug: Print the SQL query
 	query := s.db.Table("project_members").
 		Select(`
 			project_members.project_id as id,
@@ -149,29 +448,37 @@ func (s *ProjectService) GetUserInvitations(userID uint) ([]ProjectInvitation, e
 			project_members.role as role,
 			project_members.created_at as created_at
 		`).
-		Joins("LEFT JOIN projects ON projects.id = project_members.project_id").
+		Joins("JOIN projects ON projects.id = project_members.project_id AND projects.deleted_at IS NULL").
 		Where("project_members.user_id = ? AND project_members.status = ?", userID, models.MemberStatusPending)
 
-	fmt.Printf("SQL Query: %v\n", query.Statement.SQL.String())
-	fmt.Printf("Query Values: userID=%d, status=%s\n", userID, models.MemberStatusPending)
-
 	err := query.Find(&invitations).Error
 	if err != nil {
 		return nil, fmt.Errorf("error finding invitations: %w", err)
 	}
 
-	fmt.Printf("Found invitations: %+v\n", invitations)
 	return invitations, nil
 }
 
-func (s *ProjectService) AcceptInvitation(projectID, userID uint) error {
-	return s.db.Model(&models.Member{}).
-		Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, models.MemberStatusPending).
-		Update("status", models.MemberStatusAccepted).Error
+func (s *ProjectService) AcceptInvitation(projectID, userID uint, meta AuditMeta) error {
+	return s.respondToInvitation(projectID, userID, models.MemberStatusAccepted, models.AuditActionMemberAccept, meta)
 }
 
-func (s *ProjectService) RejectInvitation(projectID, userID uint) error {
-	return s.db.Model(&models.Member{}).
-		Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, models.MemberStatusPending).
-		Update("status", models.MemberStatusRejected).Error
+func (s *ProjectService) RejectInvitation(projectID, userID uint, meta AuditMeta) error {
+	return s.respondToInvitation(projectID, userID, models.MemberStatusRejected, models.AuditActionMemberReject, meta)
+}
+
+func (s *ProjectService) respondToInvitation(projectID, userID uint, status models.MemberStatus, action string, meta AuditMeta) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkProjectWritable(tx, projectID); err != nil {
+			return err
+		}
+		result := tx.Model(&models.Member{}).
+			Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, models.MemberStatusPending).
+			Update("status", status)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		after := map[string]interface{}{"status": status}
+		return recordAudit(tx, meta, action, models.AuditTargetMember, userID, &projectID, nil, after)
+	})
 } 
\ No newline at end of file
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxReportDays = 366
+
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+type BurndownPoint struct {
+	Date            string  `json:"date"`
+	RemainingCount  int64   `json:"remaining_count"`
+	RemainingPoints int64   `json:"remaining_points"`
+	IdealCount      float64 `json:"ideal_count"`
+	IdealPoints     float64 `json:"ideal_points"`
+}
+
+type BurnupPoint struct {
+	Date            string `json:"date"`
+	ScopeCount      int64  `json:"scope_count"`
+	ScopePoints     int64  `json:"scope_points"`
+	CompletedCount  int64  `json:"completed_count"`
+	CompletedPoints int64  `json:"completed_points"`
+}
+
+type CumulativeFlowPoint struct {
+	Date   string                      `json:"date"`
+	Counts map[models.TaskStatus]int64 `json:"counts"`
+	Points map[models.TaskStatus]int64 `json:"points"`
+}
+
+type SprintVelocity struct {
+	SprintID        uint       `json:"sprint_id"`
+	Name            string     `json:"name"`
+	ClosedAt        *time.Time `json:"closed_at"`
+	CommittedTasks  int64      `json:"committed_tasks"`
+	CommittedPoints int64      `json:"committed_points"`
+	CompletedTasks  int64      `json:"completed_tasks"`
+	CompletedPoints int64      `json:"completed_points"`
+}
+
+type VelocityReport struct {
+	Sprints                []SprintVelocity `json:"sprints"`
+	AverageCompletedTasks  float64          `json:"average_completed_tasks"`
+	AverageCompletedPoints float64          `json:"average_completed_points"`
+}
+
+// dailySnapshot is the state of a set of tasks at the end of one day.
+type dailySnapshot struct {
+	date   time.Time
+	counts map[models.TaskStatus]int64
+	points map[models.TaskStatus]int64
+}
+
+func (d dailySnapshot) scope() (int64, int64) {
+	var count, points int64
+	for status := range d.counts {
+		count += d.counts[status]
+		points += d.points[status]
+	}
+	return count, points
+}
+
+type reportTask struct {
+	ID          uint
+	Status      models.TaskStatus
+	StoryPoints *int
+	CreatedAt   time.Time
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// normalizeRange clamps [from, to] to whole days and rejects ranges that are
+// inverted or too long. Days after today are dropped, so a range lying fully
+// in the future yields no days.
+func normalizeRange(from, to time.Time) (time.Time, time.Time, error) {
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) || to.Sub(from) > maxReportDays*24*time.Hour {
+		return from, to, models.ErrInvalidReportRange
+	}
+	if today := truncateDay(time.Now()); to.After(today) {
+		to = today
+	}
+	return from, to, nil
+}
+
+// snapshots replays the status history of the tasks matched by scope and
+// returns one snapshot per day in [from, to].
+func (s *ReportService) snapshots(scope *gorm.DB, from, to time.Time) ([]dailySnapshot, error) {
+	var tasks []reportTask
+	if err := scope.Model(&models.Task{}).
+		Select("id, status, story_points, created_at").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	history := map[uint][]models.TaskStatusChange{}
+	if len(ids) > 0 {
+		var changes []models.TaskStatusChange
+		if err := s.db.Where("task_id IN ? AND changed_at < ?", ids, to.AddDate(0, 0, 1)).
+			Order("changed_at ASC, id ASC").
+			Find(&changes).Error; err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			history[change.TaskID] = append(history[change.TaskID], change)
+		}
+	}
+
+	var days []dailySnapshot
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, dailySnapshot{
+			date:   day,
+			counts: map[models.TaskStatus]int64{},
+			points: map[models.TaskStatus]int64{},
+		})
+	}
+
+	for _, task := range tasks {
+		var points int64
+		if task.StoryPoints != nil {
+			points = int64(*task.StoryPoints)
+		}
+
+		changes := history[task.ID]
+		// Tasks created before history was recorded only have their current status.
+		status := task.Status
+		if len(changes) > 0 {
+			status = changes[0].FromStatus
+		}
+
+		next := 0
+		for i := range days {
+			end := days[i].date.AddDate(0, 0, 1)
+			if !task.CreatedAt.Before(end) {
+				continue
+			}
+			for next < len(changes) && changes[next].ChangedAt.Before(end) {
+				status = changes[next].ToStatus
+				next++
+			}
+			if status == "" {
+				continue
+			}
+			days[i].counts[status]++
+			days[i].points[status] += points
+		}
+	}
+
+	return days, nil
+}
+
+func burndown(days []dailySnapshot, totalDays int) []BurndownPoint {
+	series := make([]BurndownPoint, 0, len(days))
+	if len(days) == 0 {
+		return series
+	}
+
+	startCount, startPoints := days[0].scope()
+	startCount -= days[0].counts[models.TaskStatusDone]
+	startPoints -= days[0].points[models.TaskStatusDone]
+
+	steps := float64(totalDays - 1)
+	for i, day := range days {
+		count, points := day.scope()
+		remainingCount := count - day.counts[models.TaskStatusDone]
+		remainingPoints := points - day.points[models.TaskStatusDone]
+
+		progress := 1.0
+		if steps > 0 {
+			progress = float64(i) / steps
+		}
+		series = append(series, BurndownPoint{
+			Date:            day.date.Format("2006-01-02"),
+			RemainingCount:  remainingCount,
+			RemainingPoints: remainingPoints,
+			IdealCount:      float64(startCount) * (1 - progress),
+			IdealPoints:     float64(startPoints) * (1 - progress),
+		})
+	}
+	return series
+}
+
+func burnup(days []dailySnapshot) []BurnupPoint {
+	series := make([]BurnupPoint, 0, len(days))
+	for _, day := range days {
+		count, points := day.scope()
+		series = append(series, BurnupPoint{
+			Date:            day.date.Format("2006-01-02"),
+			ScopeCount:      count,
+			ScopePoints:     points,
+			CompletedCount:  day.counts[models.TaskStatusDone],
+			CompletedPoints: day.points[models.TaskStatusDone],
+		})
+	}
+	return series
+}
+
+// sprintRange returns the reporting window of a sprint and its planned length in days.
+func (s *ReportService) sprintRange(sprintID uint) (*models.Sprint, time.Time, time.Time, int, error) {
+	var sprint models.Sprint
+	if err := s.db.First(&sprint, sprintID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, time.Time{}, time.Time{}, 0, models.ErrSprintNotFound
+		}
+		return nil, time.Time{}, time.Time{}, 0, err
+	}
+
+	from := sprint.StartDate
+	if from.IsZero() {
+		from = sprint.CreatedAt
+	}
+	to := sprint.EndDate
+	if sprint.ClosedAt != nil && (to.IsZero() || sprint.ClosedAt.Before(to)) {
+		to = *sprint.ClosedAt
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	planned := to
+	if !sprint.EndDate.IsZero() {
+		planned = sprint.EndDate
+	}
+	totalDays := int(truncateDay(planned).Sub(truncateDay(from))/(24*time.Hour)) + 1
+
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, from, to, 0, err
+	}
+	return &sprint, from, to, totalDays, nil
+}
+
+// sprintTasks scopes to the tasks of the sprint: the ones in it now and,
+// once it is closed, the ones that were in it at closing.
+func (s *ReportService) sprintTasks(sprintID uint) *gorm.DB {
+	closed := s.db.Model(&models.SprintTask{}).Select("task_id").Where("sprint_id = ?", sprintID)
+	return s.db.Where("(sprint_id = ? OR id IN (?))", sprintID, closed)
+}
+
+func (s *ReportService) SprintBurndown(sprintID uint) ([]BurndownPoint, error) {
+	sprint, from, to, totalDays, err := s.sprintRange(sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.snapshots(s.sprintTasks(sprint.ID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return burndown(days, totalDays), nil
+}
+
+func (s *ReportService) SprintBurnup(sprintID uint) ([]BurnupPoint, error) {
+	sprint, from, to, _, err := s.sprintRange(sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.snapshots(s.sprintTasks(sprint.ID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return burnup(days), nil
+}
+
+func (s *ReportService) ProjectBurndown(projectID uint, from, to time.Time) ([]BurndownPoint, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.snapshots(s.db.Where("project_id = ?", projectID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return burndown(days, len(days)), nil
+}
+
+func (s *ReportService) ProjectBurnup(projectID uint, from, to time.Time) ([]BurnupPoint, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.snapshots(s.db.Where("project_id = ?", projectID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return burnup(days), nil
+}
+
+func (s *ReportService) CumulativeFlow(projectID uint, from, to time.Time) ([]CumulativeFlowPoint, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.snapshots(s.db.Where("project_id = ?", projectID), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]CumulativeFlowPoint, 0, len(days))
+	for _, day := range days {
+		point := CumulativeFlowPoint{
+			Date:   day.date.Format("2006-01-02"),
+			Counts: map[models.TaskStatus]int64{},
+			Points: map[models.TaskStatus]int64{},
+		}
+		for _, status := range []models.TaskStatus{models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusDone} {
+			point.Counts[status] = day.counts[status]
+			point.Points[status] = day.points[status]
+		}
+		series = append(series, point)
+	}
+	return series, nil
+}
+
+// Velocity summarizes the last `limit` closed sprints of a project, newest first.
+func (s *ReportService) Velocity(projectID uint, limit int) (*VelocityReport, error) {
+	var sprints []models.Sprint
+	if err := s.db.Where("project_id = ? AND state = ?", projectID, models.SprintStateClosed).
+		Order("closed_at DESC").
+		Limit(limit).
+		Find(&sprints).Error; err != nil {
+		return nil, err
+	}
+
+	report := &VelocityReport{Sprints: make([]SprintVelocity, 0, len(sprints))}
+	for _, sprint := range sprints {
+		report.Sprints = append(report.Sprints, SprintVelocity{
+			SprintID:        sprint.ID,
+			Name:            sprint.Name,
+			ClosedAt:        sprint.ClosedAt,
+			CommittedTasks:  sprint.CommittedTasks,
+			CommittedPoints: sprint.CommittedPoints,
+			CompletedTasks:  sprint.CompletedTasks,
+			CompletedPoints: sprint.CompletedPoints,
+		})
+		report.AverageCompletedTasks += float64(sprint.CompletedTasks)
+		report.AverageCompletedPoints += float64(sprint.CompletedPoints)
+	}
+	if n := float64(len(sprints)); n > 0 {
+		report.AverageCompletedTasks /= n
+		report.AverageCompletedPoints /= n
+	}
+
+	return report, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"strings"
+	"taskive/models"
+
+	"gorm.io/gorm"
+)
+
+type SavedViewService struct {
+	db *gorm.DB
+}
+
+func NewSavedViewService(db *gorm.DB) *SavedViewService {
+	return &SavedViewService{db: db}
+}
+
+type CreateSavedViewInput struct {
+	Name    string   `json:"name" validate:"required,max=100"`
+	Filter  string   `json:"filter"`
+	Query   string   `json:"query"`
+	Sort    string   `json:"sort"`
+	GroupBy string   `json:"group_by" validate:"omitempty,oneof=status priority assignee sprint label"`
+	Columns []string `json:"columns"`
+	Shared  bool     `json:"shared"`
+}
+
+type UpdateSavedViewInput struct {
+	Name    string   `json:"name" validate:"omitempty,max=100"`
+	Filter  *string  `json:"filter"`
+	Query   *string  `json:"query"`
+	Sort    *string  `json:"sort"`
+	GroupBy *string  `json:"group_by" validate:"omitempty,oneof=status priority assignee sprint label"`
+	Columns []string `json:"columns"`
+	Shared  *bool    `json:"shared"`
+}
+
+// markDefault sets IsDefault on the user's default view of the project.
+func (s *SavedViewService) markDefault(projectID, userID uint, views []models.SavedView) error {
+	var def models.SavedViewDefault
+	err := s.db.Where("user_id = ? AND project_id = ?", userID, projectID).First(&def).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i := range views {
+		views[i].IsDefault = views[i].ID == def.ViewID
+	}
+	return nil
+}
+
+// GetProjectViews lists the user's own views and the views shared with the
+// project.
+func (s *SavedViewService) GetProjectViews(projectID, userID uint) ([]models.SavedView, error) {
+	var views []models.SavedView
+	if err := s.db.Where("project_id = ? AND (owner_id = ? OR shared)", projectID, userID).
+		Preload("Owner").
+		Order("name ASC").
+		Find(&views).Error; err != nil {
+		return nil, err
+	}
+	if err := s.markDefault(projectID, userID, views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// GetByID returns the view if the user is a member of its project and
+// either owns the view or it is shared.
+func (s *SavedViewService) GetByID(viewID, userID uint) (*models.SavedView, error) {
+	var view models.SavedView
+	if err := s.db.Preload("Owner").First(&view, viewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSavedViewNotFound
+		}
+		return nil, err
+	}
+
+	member, err := isProjectMember(s.db, view.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member || (view.OwnerID != userID && !view.Shared) {
+		return nil, models.ErrSavedViewNotFound
+	}
+
+	views := []models.SavedView{view}
+	if err := s.markDefault(view.ProjectID, userID, views); err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+func (s *SavedViewService) Create(projectID, ownerID uint, input CreateSavedViewInput) (*models.SavedView, error) {
+	view := &models.SavedView{
+		ProjectID: projectID,
+		OwnerID:   ownerID,
+		Name:      strings.TrimSpace(input.Name),
+		Filter:    input.Filter,
+		Query:     input.Query,
+		Sort:      input.Sort,
+		GroupBy:   input.GroupBy,
+		Columns:   models.StringList(input.Columns),
+		Shared:    input.Shared,
+	}
+	if err := checkMember(s.db, projectID, ownerID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(view).Error; err != nil {
+		return nil, err
+	}
+	return s.GetByID(view.ID, ownerID)
+}
+
+// Update changes a view. Only its owner may change it.
+func (s *SavedViewService) Update(viewID, userID uint, input UpdateSavedViewInput) (*models.SavedView, error) {
+	view, err := s.GetByID(viewID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != userID {
+		return nil, models.ErrForbidden
+	}
+
+	if name := strings.TrimSpace(input.Name); name != "" {
+		view.Name = name
+	}
+	if input.Filter != nil {
+		view.Filter = *input.Filter
+	}
+	if input.Query != nil {
+		view.Query = *input.Query
+	}
+	if input.Sort != nil {
+		view.Sort = *input.Sort
+	}
+	if input.GroupBy != nil {
+		view.GroupBy = *input.GroupBy
+	}
+	if input.Columns != nil {
+		view.Columns = models.StringList(input.Columns)
+	}
+	if input.Shared != nil {
+		view.Shared = *input.Shared
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Owner").Save(view).Error; err != nil {
+			return err
+		}
+		if view.Shared {
+			return nil
+		}
+		// Other members can no longer see a view that stopped being shared.
+		return tx.Where("view_id = ? AND user_id <> ?", view.ID, view.OwnerID).
+			Delete(&models.SavedViewDefault{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// Delete removes a view owned by the user and clears it as anyone's default.
+func (s *SavedViewService) Delete(viewID, userID uint) error {
+	view, err := s.GetByID(viewID, userID)
+	if err != nil {
+		return err
+	}
+	if view.OwnerID != userID {
+		return models.ErrForbidden
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("view_id = ?", view.ID).Delete(&models.SavedViewDefault{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SavedView{}, view.ID).Error
+	})
+}
+
+// SetDefault makes the view the user's default for the project, or clears
+// the default when viewID is nil.
+func (s *SavedViewService) SetDefault(projectID, userID uint, viewID *uint) error {
+	if viewID == nil {
+		return s.db.Where("user_id = ? AND project_id = ?", userID, projectID).
+			Delete(&models.SavedViewDefault{}).Error
+	}
+
+	view, err := s.GetByID(*viewID, userID)
+	if err != nil {
+		return err
+	}
+	if view.ProjectID != projectID {
+		return models.ErrSavedViewNotFound
+	}
+	return s.db.Save(&models.SavedViewDefault{UserID: userID, ProjectID: projectID, ViewID: view.ID}).Error
+}
+
+// GetDefault returns the user's default view of the project, or
+// ErrSavedViewNotFound when none is set.
+func (s *SavedViewService) GetDefault(projectID, userID uint) (*models.SavedView, error) {
+	var def models.SavedViewDefault
+	if err := s.db.Where("user_id = ? AND project_id = ?", userID, projectID).First(&def).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSavedViewNotFound
+		}
+		return nil, err
+	}
+	return s.GetByID(def.ViewID, userID)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"html"
+	"strings"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// searchHeadline marks matches in snippets with control characters rather
+// than HTML, so that highlightSnippet can escape the text around them.
+const searchHeadline = "StartSel=\"\x01\", StopSel=\"\x02\", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+var snippetMarks = strings.NewReplacer("\x01", "<mark>", "\x02", "</mark>")
+
+// highlightSnippet turns a headline into HTML: the text is escaped and only
+// the matches are wrapped in <mark>.
+func highlightSnippet(headline string) string {
+	return snippetMarks.Replace(html.EscapeString(headline))
+}
+
+const (
+	SearchTypeTask    = "task"
+	SearchTypeComment = "comment"
+	SearchTypeProject = "project"
+)
+
+// searchIndexes adds the generated tsvector columns and their GIN indexes.
+// Titles and names weigh more than descriptions. The "simple" configuration
+// does no stemming, so it suits text in any language.
+var searchIndexes = []string{
+	`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+		setweight(to_tsvector('simple', COALESCE(description, '')), 'B')) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)`,
+	`ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		to_tsvector('simple', COALESCE(text, ''))) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector)`,
+	`ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+		setweight(to_tsvector('simple', COALESCE(description, '')), 'B')) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_projects_search_vector ON projects USING GIN (search_vector)`,
+}
+
+// MigrateSearchIndexes creates the full-text search columns and indexes.
+// It is safe to run on every start.
+func MigrateSearchIndexes(db *gorm.DB) error {
+	for _, statement := range searchIndexes {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchSources selects matching rows per result type. Every source yields
+// the same columns so they can be combined with UNION ALL.
+var searchSources = map[string]string{
+	SearchTypeTask: `SELECT 'task' AS type, tasks.id, tasks.project_id, tasks.id AS task_id,
+			tasks.title AS title, tasks.title || ' ' || COALESCE(tasks.description, '') AS body,
+			ts_rank(tasks.search_vector, tsq) AS rank, tasks.updated_at
+		FROM tasks, websearch_to_tsquery('simple', @q) tsq
+		WHERE tasks.search_vector @@ tsq AND tasks.deleted_at IS NULL AND tasks.project_id IN (@projects)`,
+	SearchTypeComment: `SELECT 'comment' AS type, comments.id, tasks.project_id, tasks.id AS task_id,
+			tasks.title AS title, comments.text AS body,
+			ts_rank(comments.search_vector, tsq) AS rank, comments.updated_at
+		FROM comments JOIN tasks ON tasks.id = comments.task_id, websearch_to_tsquery('simple', @q) tsq
+		WHERE comments.search_vector @@ tsq AND comments.deleted_at IS NULL AND tasks.deleted_at IS NULL AND tasks.project_id IN (@projects)`,
+	SearchTypeProject: `SELECT 'project' AS type, projects.id, projects.id AS project_id, NULL::bigint AS task_id,
+			projects.name AS title, projects.name || ' ' || COALESCE(projects.description, '') AS body,
+			ts_rank(projects.search_vector, tsq) AS rank, projects.updated_at
+		FROM projects, websearch_to_tsquery('simple', @q) tsq
+		WHERE projects.search_vector @@ tsq AND projects.deleted_at IS NULL AND projects.id IN (@projects)`,
+}
+
+var searchTypeOrder = []string{SearchTypeTask, SearchTypeComment, SearchTypeProject}
+
+type SearchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// SearchResult is a task, comment or project matching a search. TaskID is
+// set for tasks and comments; Snippet highlights the matched words.
+type SearchResult struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	ProjectID uint      `json:"project_id"`
+	TaskID    *uint     `json:"task_id,omitempty"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SearchPage struct {
+	Items  []SearchResult `json:"items"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// SearchParams narrows a search. Empty Types searches everything.
+type SearchParams struct {
+	Types  []string
+	Limit  int
+	Offset int
+}
+
+// Search finds tasks, comments and projects matching q in the projects the
+// user is an accepted member of, best matches first. q uses web search
+// syntax: quoted phrases, "or" and a leading "-" to exclude words.
+func (s *SearchService) Search(userID uint, q string, params SearchParams) (*SearchPage, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, models.ErrSearchQuery
+	}
+
+	selected := map[string]bool{}
+	for _, t := range params.Types {
+		if _, ok := searchSources[t]; !ok {
+			return nil, models.ErrSearchType
+		}
+		selected[t] = true
+	}
+	var sources []string
+	for _, t := range searchTypeOrder {
+		if len(selected) == 0 || selected[t] {
+			sources = append(sources, searchSources[t])
+		}
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	args := map[string]interface{}{
+		"q": q,
+		"projects": s.db.Model(&models.Member{}).
+			Select("project_id").
+			Where("user_id = ? AND status = ?", userID, models.MemberStatusAccepted),
+		"headline": searchHeadline,
+		"limit":    limit,
+		"offset":   offset,
+	}
+	hits := strings.Join(sources, " UNION ALL ")
+
+	page := &SearchPage{Items: []SearchResult{}, Limit: limit, Offset: offset}
+	if err := s.db.Raw("SELECT COUNT(*) FROM ("+hits+") hits", args).Scan(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	if page.Total == 0 {
+		return page, nil
+	}
+
+	// Headlines are costly, so they are built only for the returned page.
+	err := s.db.Raw(`SELECT type, id, project_id, task_id, title, rank, updated_at,
+			ts_headline('simple', body, websearch_to_tsquery('simple', @q), @headline) AS snippet
+		FROM (`+hits+` ORDER BY rank DESC, updated_at DESC, id DESC LIMIT @limit OFFSET @offset) hits
+		ORDER BY rank DESC, updated_at DESC, id DESC`, args).
+		Scan(&page.Items).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		page.Items[i].Snippet = highlightSnippet(page.Items[i].Snippet)
+	}
+	return page, nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SprintService struct {
+	db *gorm.DB
+}
+
+func NewSprintService(db *gorm.DB) *SprintService {
+	return &SprintService{db: db}
+}
+
+type CreateSprintInput struct {
+	Name      string    `json:"name" validate:"required"`
+	Goal      string    `json:"goal"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type UpdateSprintInput struct {
+	Name      string    `json:"name"`
+	Goal      string    `json:"goal"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type AssignSprintTasksInput struct {
+	TaskIDs []uint `json:"task_ids" validate:"required,min=1"`
+}
+
+type CloseSprintInput struct {
+	// NextSprintID receives the unfinished tasks. When nil they go back to the backlog.
+	NextSprintID *uint `json:"next_sprint_id"`
+}
+
+type CloseSprintResult struct {
+	Sprint          *models.Sprint `json:"sprint"`
+	CompletedTasks  int64          `json:"completed_tasks"`
+	CompletedPoints int64          `json:"completed_points"`
+	RolledOverTasks int64          `json:"rolled_over_tasks"`
+	NextSprintID    *uint          `json:"next_sprint_id"`
+}
+
+// checkSprintAssignable makes sure tasks of projectID may be scheduled into sprintID.
+func checkSprintAssignable(db *gorm.DB, projectID, sprintID uint) error {
+	var sprint models.Sprint
+	if err := db.First(&sprint, sprintID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return models.ErrSprintNotFound
+		}
+		return err
+	}
+	if sprint.ProjectID != projectID {
+		return models.ErrSprintProject
+	}
+	if sprint.State == models.SprintStateClosed {
+		return models.ErrSprintClosed
+	}
+	return nil
+}
+
+func (s *SprintService) Create(projectID uint, input CreateSprintInput) (*models.Sprint, error) {
+	if err := checkProjectWritable(s.db, projectID); err != nil {
+		return nil, err
+	}
+	sprint := &models.Sprint{
+		ProjectID: projectID,
+		Name:      input.Name,
+		Goal:      input.Goal,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+		State:     models.SprintStatePlanned,
+	}
+
+	if err := s.db.Create(sprint).Error; err != nil {
+		return nil, err
+	}
+
+	return sprint, nil
+}
+
+func (s *SprintService) Update(sprintID uint, input UpdateSprintInput) (*models.Sprint, error) {
+	sprint, err := s.GetByID(sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State == models.SprintStateClosed {
+		return nil, models.ErrSprintClosed
+	}
+	if err := checkProjectWritable(s.db, sprint.ProjectID); err != nil {
+		return nil, err
+	}
+
+	if input.Name != "" {
+		sprint.Name = input.Name
+	}
+	sprint.Goal = input.Goal
+	if !input.StartDate.IsZero() {
+		sprint.StartDate = input.StartDate
+	}
+	if !input.EndDate.IsZero() {
+		sprint.EndDate = input.EndDate
+	}
+
+	if err := s.db.Save(sprint).Error; err != nil {
+		return nil, err
+	}
+
+	return sprint, nil
+}
+
+// sprintChange is the column update moving tasks to sprintID, or to the
+// backlog when nil. It bumps their versions since sprint_id is part of the
+// task representation.
+func sprintChange(sprintID *uint) map[string]interface{} {
+	return map[string]interface{}{
+		"sprint_id": sprintID,
+		"version":   gorm.Expr("version + 1"),
+	}
+}
+
+// Delete removes the sprint and moves its tasks back to the backlog.
+func (s *SprintService) Delete(sprintID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var sprint models.Sprint
+		if err := tx.Select("id, project_id").First(&sprint, sprintID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrSprintNotFound
+			}
+			return err
+		}
+		if err := checkProjectWritable(tx, sprint.ProjectID); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Task{}).Where("sprint_id = ?", sprintID).
+			Updates(sprintChange(nil)).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("sprint_id = ?", sprintID).Delete(&models.SprintTask{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Sprint{}, sprintID).Error
+	})
+}
+
+func (s *SprintService) GetByID(sprintID uint) (*models.Sprint, error) {
+	var sprint models.Sprint
+	if err := s.db.First(&sprint, sprintID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSprintNotFound
+		}
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (s *SprintService) GetProjectSprints(projectID uint) ([]models.Sprint, error) {
+	var sprints []models.Sprint
+	err := s.db.Where("project_id = ?", projectID).
+		Order("start_date ASC, id ASC").
+		Find(&sprints).Error
+	return sprints, err
+}
+
+func (s *SprintService) GetSprintTasks(sprintID uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := s.db.Where("sprint_id = ?", sprintID).
+		Preload("Assignee").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// GetBacklog returns the project's tasks that are not scheduled into any sprint.
+func (s *SprintService) GetBacklog(projectID uint) ([]models.Task, error) {
+	var tasks []models.Task
+	err := s.db.Where("project_id = ? AND sprint_id IS NULL", projectID).
+		Preload("Assignee").
+		Order("created_at ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (s *SprintService) AssignTasks(sprintID uint, taskIDs []uint) error {
+	sprint, err := s.GetByID(sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State == models.SprintStateClosed {
+		return models.ErrSprintClosed
+	}
+	if err := checkProjectWritable(s.db, sprint.ProjectID); err != nil {
+		return err
+	}
+
+	return s.db.Model(&models.Task{}).
+		Where("id IN ? AND project_id = ?", taskIDs, sprint.ProjectID).
+		Updates(sprintChange(&sprint.ID)).Error
+}
+
+// UnassignTasks moves the given tasks from the sprint back to the backlog.
+func (s *SprintService) UnassignTasks(sprintID uint, taskIDs []uint) error {
+	sprint, err := s.GetByID(sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State == models.SprintStateClosed {
+		return models.ErrSprintClosed
+	}
+	if err := checkProjectWritable(s.db, sprint.ProjectID); err != nil {
+		return err
+	}
+
+	return s.db.Model(&models.Task{}).
+		Where("id IN ? AND sprint_id = ?", taskIDs, sprint.ID).
+		Updates(sprintChange(nil)).Error
+}
+
+func (s *SprintService) Start(sprintID uint) (*models.Sprint, error) {
+	var sprint models.Sprint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&sprint, sprintID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrSprintNotFound
+			}
+			return err
+		}
+		if sprint.State != models.SprintStatePlanned {
+			return models.ErrSprintNotPlanned
+		}
+		if err := checkProjectWritable(tx, sprint.ProjectID); err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.Sprint{}).
+			Where("project_id = ? AND state = ?", sprint.ProjectID, models.SprintStateActive).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return models.ErrSprintAlreadyActive
+		}
+
+		sprint.State = models.SprintStateActive
+		if sprint.StartDate.IsZero() {
+			sprint.StartDate = time.Now()
+		}
+		return tx.Save(&sprint).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// Close finishes an active sprint. Tasks that are not DONE roll over into
+// input.NextSprintID, or back to the backlog when it is not given.
+func (s *SprintService) Close(sprintID uint, input CloseSprintInput) (*CloseSprintResult, error) {
+	var sprint models.Sprint
+	result := &CloseSprintResult{NextSprintID: input.NextSprintID}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&sprint, sprintID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrSprintNotFound
+			}
+			return err
+		}
+		if sprint.State != models.SprintStateActive {
+			return models.ErrSprintNotActive
+		}
+		if err := checkProjectWritable(tx, sprint.ProjectID); err != nil {
+			return err
+		}
+
+		var next *uint
+		if input.NextSprintID != nil {
+			if *input.NextSprintID == sprint.ID {
+				return models.ErrSprintRollover
+			}
+			if err := checkSprintAssignable(tx, sprint.ProjectID, *input.NextSprintID); err != nil {
+				return err
+			}
+			next = input.NextSprintID
+		}
+
+		var done struct {
+			Count  int64
+			Points int64
+		}
+		if err := tx.Model(&models.Task{}).
+			Select("COUNT(*) AS count, COALESCE(SUM(story_points), 0) AS points").
+			Where("sprint_id = ? AND status = ?", sprint.ID, models.TaskStatusDone).
+			Scan(&done).Error; err != nil {
+			return err
+		}
+		result.CompletedTasks = done.Count
+		result.CompletedPoints = done.Points
+
+		var committed struct {
+			Count  int64
+			Points int64
+		}
+		if err := tx.Model(&models.Task{}).
+			Select("COUNT(*) AS count, COALESCE(SUM(story_points), 0) AS points").
+			Where("sprint_id = ?", sprint.ID).
+			Scan(&committed).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`INSERT INTO sprint_tasks (sprint_id, task_id)
+			SELECT sprint_id, id FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL
+			ON CONFLICT DO NOTHING`, sprint.ID).Error; err != nil {
+			return err
+		}
+
+		rollover := tx.Model(&models.Task{}).
+			Where("sprint_id = ? AND status <> ?", sprint.ID, models.TaskStatusDone).
+			Updates(sprintChange(next))
+		if rollover.Error != nil {
+			return rollover.Error
+		}
+		result.RolledOverTasks = rollover.RowsAffected
+
+		now := time.Now()
+		sprint.State = models.SprintStateClosed
+		sprint.ClosedAt = &now
+		sprint.CommittedTasks = committed.Count
+		sprint.CommittedPoints = committed.Points
+		sprint.CompletedTasks = done.Count
+		sprint.CompletedPoints = done.Points
+		return tx.Save(&sprint).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Sprint = &sprint
+	return result, nil
+}
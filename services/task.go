@@ -1,18 +1,25 @@
 package services
 
 import (
+	"strings"
 	"taskive/models"
+	"taskive/storage"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaskService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	store storage.Storage
+	// attachmentQuota is the per-project attachment quota that moved and
+	// copied tasks must fit in.
+	attachmentQuota int64
 }
 
-func NewTaskService(db *gorm.DB) *TaskService {
-	return &TaskService{db: db}
+func NewTaskService(db *gorm.DB, store storage.Storage, attachmentQuota int64) *TaskService {
+	return &TaskService{db: db, store: store, attachmentQuota: attachmentQuota}
 }
 
 type CreateTaskInput struct {
@@ -22,6 +29,9 @@ type CreateTaskInput struct {
 	Priority    models.TaskPriority `json:"priority"`
 	DueDate     time.Time         `json:"due_date"`
 	AssigneeID  *uint            `json:"assignee_id"`
+	SprintID    *uint            `json:"sprint_id"`
+	StoryPoints *int             `json:"story_points" validate:"omitempty,min=0"`
+	CustomFields CustomFieldValues `json:"custom_fields"`
 }
 
 type UpdateTaskInput struct {
@@ -31,9 +41,12 @@ type UpdateTaskInput struct {
 	Priority    models.TaskPriority `json:"priority"`
 	DueDate     time.Time         `json:"due_date"`
 	AssigneeID  *uint            `json:"assignee_id"`
+	SprintID    *uint            `json:"sprint_id"`
+	StoryPoints *int             `json:"story_points" validate:"omitempty,min=0"`
+	CustomFields CustomFieldValues `json:"custom_fields"`
 }
 
-func (s *TaskService) Create(projectID uint, input CreateTaskInput) (*models.Task, error) {
+func (s *TaskService) Create(projectID, actorID uint, input CreateTaskInput) (*models.Task, error) {
 	task := &models.Task{
 		ProjectID:   projectID,
 		Title:       input.Title,
@@ -42,20 +55,70 @@ func (s *TaskService) Create(projectID uint, input CreateTaskInput) (*models.Tas
 		Priority:    input.Priority,
 		DueDate:     input.DueDate,
 		AssigneeID:  input.AssigneeID,
+		SprintID:    input.SprintID,
+		StoryPoints: input.StoryPoints,
 	}
 
-	if err := s.db.Create(task).Error; err != nil {
+	if err := checkProjectWritable(s.db, projectID); err != nil {
 		return nil, err
 	}
+	if task.SprintID != nil {
+		if err := checkSprintAssignable(s.db, projectID, *task.SprintID); err != nil {
+			return nil, err
+		}
+	}
 
-	return task, nil
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, task, "", task.Status); err != nil {
+			return err
+		}
+		if err := setCustomFieldValues(tx, task, actorID, input.CustomFields, true); err != nil {
+			return err
+		}
+		if err := syncMentions(tx, task, nil, actorID, task.Description); err != nil {
+			return err
+		}
+		return recordActivity(tx, task, actorID, models.TaskActivityCreated, "", nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{*task}
+	if err := attachCustomFieldValues(s.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// Update changes the task. A non-zero version makes the update conditional:
+// it fails with ErrVersionConflict once the task has changed since the
+// caller read that version. Without one, an update that races another
+// write is redone on the fresh task.
+func (s *TaskService) Update(taskID, actorID uint, input UpdateTaskInput, version int) (*models.Task, error) {
+	var result *models.Task
+	err := retryUnconditional(version, func() (err error) {
+		result, err = s.update(taskID, actorID, input, version)
+		return err
+	})
+	return result, err
 }
 
-func (s *TaskService) Update(taskID uint, input UpdateTaskInput) (*models.Task, error) {
+func (s *TaskService) update(taskID, actorID uint, input UpdateTaskInput, version int) (*models.Task, error) {
 	var task models.Task
 	if err := s.db.First(&task, taskID).Error; err != nil {
 		return nil, err
 	}
+	if version != 0 && task.Version != version {
+		return nil, models.ErrVersionConflict
+	}
+	if err := checkProjectWritable(s.db, task.ProjectID); err != nil {
+		return nil, err
+	}
+	before := task
 
 	if input.Title != "" {
 		task.Title = input.Title
@@ -71,42 +134,207 @@ func (s *TaskService) Update(taskID uint, input UpdateTaskInput) (*models.Task,
 		task.DueDate = input.DueDate
 	}
 	task.AssigneeID = input.AssigneeID
+	if input.SprintID != nil {
+		if err := checkSprintAssignable(s.db, task.ProjectID, *input.SprintID); err != nil {
+			return nil, err
+		}
+		task.SprintID = input.SprintID
+	}
+	if input.StoryPoints != nil {
+		task.StoryPoints = input.StoryPoints
+	}
 
-	if err := s.db.Save(&task).Error; err != nil {
+	task.Version++
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &task, before.Version); err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, &task, before.Status, task.Status); err != nil {
+			return err
+		}
+		if err := recordTaskChanges(tx, actorID, &before, &task); err != nil {
+			return err
+		}
+		if task.Description != before.Description {
+			if err := syncMentions(tx, &task, nil, actorID, task.Description); err != nil {
+				return err
+			}
+		}
+		return setCustomFieldValues(tx, &task, actorID, input.CustomFields, false)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &task, nil
+	tasks := []models.Task{task}
+	if err := attachCustomFieldValues(s.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
 }
 
-func (s *TaskService) Delete(taskID uint) error {
+// Delete moves the task and its live comments to the trash.
+func (s *TaskService) Delete(taskID, actorID uint) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("task_id = ?", taskID).Delete(&models.Comment{}).Error; err != nil {
+		var task models.Task
+		if err := tx.First(&task, taskID).Error; err != nil {
 			return err
 		}
-		if err := tx.Delete(&models.Task{}, taskID).Error; err != nil {
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
 			return err
 		}
-		return nil
+		return trashTask(tx, &task, actorID)
 	})
 }
 
-func (s *TaskService) GetProjectTasks(projectID uint) ([]models.Task, error) {
-	var tasks []models.Task
-	err := s.db.Where("project_id = ?", projectID).
-		Preload("Assignee").
-		Find(&tasks).Error
-	return tasks, err
+// trashTask soft-deletes the task and its live comments with one shared
+// timestamp so that restoring the task brings back exactly those comments.
+func trashTask(tx *gorm.DB, task *models.Task, actorID uint) error {
+	title := task.Title
+	if err := recordActivity(tx, task, actorID, models.TaskActivityDeleted, "title", &title, nil); err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Microsecond)
+	if err := tx.Model(&models.Comment{}).Where("task_id = ?", task.ID).
+		UpdateColumn("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(task).UpdateColumn("deleted_at", now).Error
+}
+
+// purgeTask permanently removes a task with its comments, checklist, labels,
+// custom field values and attachments, and returns the blobs to remove
+// after commit.
+func purgeTask(tx *gorm.DB, taskID uint) ([]string, error) {
+	taskComments := tx.Unscoped().Model(&models.Comment{}).Select("id").Where("task_id = ?", taskID)
+	if err := tx.Where("comment_id IN (?)", taskComments).Delete(&models.CommentRevision{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("task_id = ?", taskID).Delete(&models.Comment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskStatusChange{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.SprintTask{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.ChecklistItem{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_labels WHERE task_id = ?", taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.CustomFieldValue{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskKeyAlias{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskActivity{}).Error; err != nil {
+		return nil, err
+	}
+	keys, err := purgeAttachments(tx, "task_id = ?", taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Task{}, taskID).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetProjectTasks returns one page of the project's tasks matching the
+// filter. Pass the previous page's NextCursor to get the following page.
+func (s *TaskService) GetProjectTasks(projectID uint, filter TaskFilter) (*TaskPage, error) {
+	query, err := s.filterTasks(projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := filter.Sort
+	if strings.TrimSpace(filter.Query) != "" {
+		var querySort []TaskSort
+		if query, querySort, err = applyTaskQuery(query, filter.Query, filter.UserID); err != nil {
+			return nil, err
+		}
+		if len(sort) == 0 {
+			sort = querySort
+		}
+	}
+	return s.pageTasks(query, projectID, sort, filter.Cursor, filter.Limit)
 }
 
 func (s *TaskService) GetByID(taskID uint) (*models.Task, error) {
 	var task models.Task
-	if err := s.db.Preload("Assignee").First(&task, taskID).Error; err != nil {
+	if err := s.db.Preload("Assignee").Preload("Labels").First(&task, taskID).Error; err != nil {
 		return nil, err
 	}
-	return &task, nil
+	tasks := []models.Task{task}
+	if err := attachChecklistSummaries(s.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := attachCustomFieldValues(s.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// UpdateStatus sets the task status. A non-zero version makes it
+// conditional like Update.
+func (s *TaskService) UpdateStatus(taskID, actorID uint, status models.TaskStatus, version int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, taskID).Error; err != nil {
+			return err
+		}
+		if version != 0 && task.Version != version {
+			return models.ErrVersionConflict
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+		before := task
+
+		task.Status = status
+		task.Version++
+		if err := saveVersioned(tx, &task, before.Version); err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, &task, before.Status, status); err != nil {
+			return err
+		}
+		return recordTaskChanges(tx, actorID, &before, &task)
+	})
 }
 
-func (s *TaskService) UpdateStatus(taskID uint, status models.TaskStatus) error {
-	return s.db.Model(&models.Task{}).Where("id = ?", taskID).Update("status", status).Error
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// recordStatusChange appends to the task's status history when the status
+// actually changed. Reports rebuild past board states from this history.
+func recordStatusChange(tx *gorm.DB, task *models.Task, from, to models.TaskStatus) error {
+	if from == to {
+		return nil
+	}
+	return tx.Create(&models.TaskStatusChange{
+		TaskID:     task.ID,
+		ProjectID:  task.ProjectID,
+		FromStatus: from,
+		ToStatus:   to,
+	}).Error
 } 
\ No newline at end of file
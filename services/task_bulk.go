@@ -0,0 +1,216 @@
+package services
+
+import (
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxBulkTasks = 500
+
+const (
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+
+	// BulkModeAtomic applies every change or none of them.
+	BulkModeAtomic = "atomic"
+	// BulkModeBestEffort keeps the changes that succeeded.
+	BulkModeBestEffort = "best_effort"
+)
+
+// BulkTaskInput selects tasks either by ID or with a task query such as
+// status = TODO AND priority = HIGH, and applies one action to them.
+type BulkTaskInput struct {
+	TaskIDs []uint          `json:"task_ids" validate:"omitempty,max=500"`
+	Query   string          `json:"query"`
+	Action  string          `json:"action" validate:"required,oneof=update delete"`
+	Mode    string          `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Changes BulkTaskChanges `json:"changes"`
+}
+
+// BulkTaskChanges lists the fields to set for the update action. Fields
+// left out stay as they are.
+type BulkTaskChanges struct {
+	Status         *models.TaskStatus   `json:"status" validate:"omitempty,oneof=TODO IN_PROGRESS DONE"`
+	Priority       *models.TaskPriority `json:"priority" validate:"omitempty,oneof=LOW MEDIUM HIGH"`
+	AssigneeID     *uint                `json:"assignee_id"`
+	Unassign       bool                 `json:"unassign"`
+	DueDate        *time.Time           `json:"due_date"`
+	ClearDueDate   bool                 `json:"clear_due_date"`
+	AddLabelIDs    []uint               `json:"add_label_ids"`
+	RemoveLabelIDs []uint               `json:"remove_label_ids"`
+}
+
+func (c BulkTaskChanges) empty() bool {
+	return c.Status == nil && c.Priority == nil && c.AssigneeID == nil && !c.Unassign &&
+		c.DueDate == nil && !c.ClearDueDate && len(c.AddLabelIDs) == 0 && len(c.RemoveLabelIDs) == 0
+}
+
+type BulkItemResult struct {
+	TaskID uint   `json:"task_id"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkTaskResult reports the outcome per task. Applied is false when an
+// atomic operation was rolled back.
+type BulkTaskResult struct {
+	Mode      string           `json:"mode"`
+	Applied   bool             `json:"applied"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// bulkTaskIDs resolves the tasks the operation applies to.
+func (s *TaskService) bulkTaskIDs(projectID, actorID uint, input BulkTaskInput) ([]uint, error) {
+	if (len(input.TaskIDs) > 0) == (input.Query != "") {
+		return nil, models.ErrBulkTarget
+	}
+	if len(input.TaskIDs) > 0 {
+		return uniqueIDs(input.TaskIDs), nil
+	}
+
+	query, err := s.filterTasks(projectID, TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if query, _, err = applyTaskQuery(query, input.Query, actorID); err != nil {
+		return nil, err
+	}
+	var ids []uint
+	if err := query.Order("tasks.id ASC").Limit(maxBulkTasks+1).Pluck("tasks.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > maxBulkTasks {
+		return nil, models.ErrBulkTooMany
+	}
+	return ids, nil
+}
+
+// Bulk applies an update or delete to many tasks of the project. Each task
+// runs in its own savepoint, so one failing task is reported without
+// aborting the others; in atomic mode any failure rolls everything back and
+// ErrBulkFailed is returned together with the report.
+func (s *TaskService) Bulk(projectID, actorID uint, input BulkTaskInput) (*BulkTaskResult, error) {
+	if input.Mode == "" {
+		input.Mode = BulkModeAtomic
+	}
+	if input.Action == BulkActionUpdate && input.Changes.empty() {
+		return nil, models.ErrBulkNoChanges
+	}
+	if err := checkMember(s.db, projectID, actorID); err != nil {
+		return nil, err
+	}
+	if err := checkProjectWritable(s.db, projectID); err != nil {
+		return nil, err
+	}
+
+	changes := input.Changes
+	if input.Action == BulkActionUpdate {
+		if changes.AssigneeID != nil && !changes.Unassign {
+			member, err := isProjectMember(s.db, projectID, *changes.AssigneeID)
+			if err != nil {
+				return nil, err
+			}
+			if !member {
+				return nil, models.ErrAssigneeNotMember
+			}
+		}
+		labelIDs := append(append([]uint{}, changes.AddLabelIDs...), changes.RemoveLabelIDs...)
+		if _, err := findProjectLabels(s.db, projectID, uniqueIDs(labelIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := s.bulkTaskIDs(projectID, actorID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkTaskResult{Mode: input.Mode, Results: make([]BulkItemResult, 0, len(ids))}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			err := tx.Transaction(func(item *gorm.DB) error {
+				return applyBulkAction(item, projectID, id, actorID, input.Action, changes)
+			})
+			if err == gorm.ErrRecordNotFound {
+				err = models.ErrTaskNotFound
+			}
+			if err != nil {
+				result.Failed++
+				result.Results = append(result.Results, BulkItemResult{TaskID: id, Error: err.Error()})
+				continue
+			}
+			result.Succeeded++
+			result.Results = append(result.Results, BulkItemResult{TaskID: id, OK: true})
+		}
+		if input.Mode == BulkModeAtomic && result.Failed > 0 {
+			return models.ErrBulkFailed
+		}
+		return nil
+	})
+	if err == models.ErrBulkFailed {
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+func applyBulkAction(tx *gorm.DB, projectID, taskID, actorID uint, action string, changes BulkTaskChanges) error {
+	var task models.Task
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectID).First(&task, taskID).Error; err != nil {
+		return err
+	}
+	if action == BulkActionDelete {
+		return trashTask(tx, &task, actorID)
+	}
+	before := task
+
+	if changes.Status != nil {
+		task.Status = *changes.Status
+	}
+	if changes.Priority != nil {
+		task.Priority = *changes.Priority
+	}
+	if changes.Unassign {
+		task.AssigneeID = nil
+	} else if changes.AssigneeID != nil {
+		task.AssigneeID = changes.AssigneeID
+	}
+	if changes.ClearDueDate {
+		task.DueDate = time.Time{}
+	} else if changes.DueDate != nil {
+		task.DueDate = *changes.DueDate
+	}
+
+	task.Version++
+	if err := tx.Model(&task).
+		Select("status", "priority", "assignee_id", "due_date", "version", "updated_at").
+		Updates(&task).Error; err != nil {
+		return err
+	}
+	if err := recordStatusChange(tx, &task, before.Status, task.Status); err != nil {
+		return err
+	}
+	if err := recordTaskChanges(tx, actorID, &before, &task); err != nil {
+		return err
+	}
+
+	if len(changes.RemoveLabelIDs) > 0 {
+		if err := tx.Exec("DELETE FROM task_labels WHERE task_id = ? AND label_id IN ?", task.ID, changes.RemoveLabelIDs).Error; err != nil {
+			return err
+		}
+	}
+	for _, labelID := range changes.AddLabelIDs {
+		if err := tx.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?) ON CONFLICT DO NOTHING", task.ID, labelID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
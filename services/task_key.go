@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"taskive/models"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const maxProjectKeyLength = 10
+
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// normalizeProjectKey upper-cases the key and checks its format: a letter
+// followed by 1-9 letters or digits.
+func normalizeProjectKey(key string) (string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if !projectKeyPattern.MatchString(key) {
+		return "", models.ErrInvalidProjectKey
+	}
+	return key, nil
+}
+
+func projectKeyTaken(tx *gorm.DB, key string) (bool, error) {
+	var count int64
+	err := tx.Unscoped().Model(&models.Project{}).Where("key = ?", key).Count(&count).Error
+	return count > 0, err
+}
+
+// generateProjectKey derives a free key from the project name: the initials
+// of a multi-word name, otherwise its first letters, e.g. "Web Shop" -> WS
+// and "Website" -> WEBS. A number is appended when the key is taken.
+func generateProjectKey(tx *gorm.DB, name string) (string, error) {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+
+	var base string
+	if len(words) > 1 {
+		for _, word := range words {
+			base += word[:1]
+		}
+	} else if len(words) == 1 {
+		base = words[0]
+		if len(base) > 4 {
+			base = base[:4]
+		}
+	}
+	base = strings.TrimLeft(base, "0123456789")
+	if len(base) > maxProjectKeyLength-2 {
+		base = base[:maxProjectKeyLength-2]
+	}
+	if len(base) < 2 {
+		base = "PRJ"
+	}
+
+	for i := 1; ; i++ {
+		key := base
+		if i > 1 {
+			key = fmt.Sprintf("%s%d", base, i)
+		}
+		taken, err := projectKeyTaken(tx, key)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return key, nil
+		}
+	}
+}
+
+// resolveProjectKey validates a requested key or generates one from the
+// project name when none was given.
+func resolveProjectKey(tx *gorm.DB, requested, name string) (string, error) {
+	if requested == "" {
+		return generateProjectKey(tx, name)
+	}
+	key, err := normalizeProjectKey(requested)
+	if err != nil {
+		return "", err
+	}
+	taken, err := projectKeyTaken(tx, key)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", models.ErrProjectKeyExists
+	}
+	return key, nil
+}
+
+// addTaskKeyAliases keeps the current keys of the matching tasks resolvable.
+func addTaskKeyAliases(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Exec(`INSERT INTO task_key_aliases (key, task_id, created_at)
+		SELECT key, id, NOW() FROM tasks WHERE key IS NOT NULL AND `+query+`
+		ON CONFLICT (key) DO UPDATE SET task_id = EXCLUDED.task_id`, args...).Error
+}
+
+// changeProjectKey re-keys every task of the project, trashed ones included,
+// and keeps the old keys as aliases.
+func changeProjectKey(tx *gorm.DB, project *models.Project, key string) error {
+	if err := addTaskKeyAliases(tx, "project_id = ?", project.ID); err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE tasks SET key = ? || '-' || number, version = version + 1 WHERE project_id = ?", key, project.ID).Error; err != nil {
+		return err
+	}
+	project.Key = key
+	return tx.Model(project).Update("key", key).Error
+}
+
+// MigrateTaskKeys gives projects without a key a generated one and numbers
+// their existing tasks in creation order. It is safe to run on every start.
+func MigrateTaskKeys(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var projects []models.Project
+		if err := tx.Unscoped().Where("key IS NULL OR key = ''").Order("id ASC").Find(&projects).Error; err != nil {
+			return err
+		}
+		for i := range projects {
+			key, err := generateProjectKey(tx, projects[i].Name)
+			if err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&projects[i]).UpdateColumn("key", key).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec(`UPDATE tasks SET number = numbered.number, key = projects.key || '-' || numbered.number,
+				version = tasks.version + 1
+			FROM (
+				SELECT tasks.id, tasks.project_id, projects.task_sequence +
+					ROW_NUMBER() OVER (PARTITION BY tasks.project_id ORDER BY tasks.created_at, tasks.id) AS number
+				FROM tasks JOIN projects ON projects.id = tasks.project_id
+				WHERE tasks.number IS NULL OR tasks.number = 0
+			) numbered, projects
+			WHERE tasks.id = numbered.id AND projects.id = numbered.project_id`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE projects SET task_sequence = numbered.number
+			FROM (SELECT project_id, MAX(number) AS number FROM tasks GROUP BY project_id) numbered
+			WHERE projects.id = numbered.project_id AND projects.task_sequence < numbered.number`).Error
+	})
+}
+
+// GetByKey finds a task by its key, e.g. WEB-42, or by a former key.
+func (s *TaskService) GetByKey(key string) (*models.Task, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+
+	var task models.Task
+	err := s.db.Select("id").Where("key = ?", key).First(&task).Error
+	if err == gorm.ErrRecordNotFound {
+		var alias models.TaskKeyAlias
+		if err := s.db.Where("key = ?", key).First(&alias).Error; err != nil {
+			return nil, err
+		}
+		task.ID = alias.TaskID
+	} else if err != nil {
+		return nil, err
+	}
+	return s.GetByID(task.ID)
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"taskive/models"
+	"taskive/storage"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferTaskInput struct {
+	ProjectID uint `json:"project_id" validate:"required"`
+}
+
+// checkTransfer makes sure the actor may take the task into the target
+// project and that its assignee can follow it there.
+func checkTransfer(tx *gorm.DB, task *models.Task, projectID, actorID uint) error {
+	if task.ProjectID == projectID {
+		return models.ErrMoveSameProject
+	}
+	if err := tx.First(&models.Project{}, projectID).Error; err != nil {
+		return err
+	}
+	if err := checkMember(tx, task.ProjectID, actorID); err != nil {
+		return err
+	}
+	if err := checkMember(tx, projectID, actorID); err != nil {
+		return err
+	}
+	if err := checkProjectWritable(tx, projectID); err != nil {
+		return err
+	}
+	if task.AssigneeID != nil {
+		member, err := isProjectMember(tx, projectID, *task.AssigneeID)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return models.ErrAssigneeNotMember
+		}
+	}
+	return nil
+}
+
+// mapLabels returns the IDs of the target project's labels with the same
+// names, creating the ones it does not have yet.
+func mapLabels(tx *gorm.DB, labels []models.Label, projectID uint) ([]uint, error) {
+	ids := make([]uint, 0, len(labels))
+	for _, label := range labels {
+		name, err := labelName(label.Name)
+		if err != nil {
+			// Blank labels are not carried over.
+			continue
+		}
+		var target models.Label
+		err = tx.Where("project_id = ? AND LOWER(name) = LOWER(?)", projectID, name).First(&target).Error
+		if err == gorm.ErrRecordNotFound {
+			target = models.Label{
+				ProjectID:   projectID,
+				Name:        name,
+				Color:       label.Color,
+				Description: label.Description,
+			}
+			err = tx.Create(&target).Error
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, target.ID)
+	}
+	return ids, nil
+}
+
+func setTaskLabelIDs(tx *gorm.DB, taskID uint, labelIDs []uint) error {
+	if err := tx.Exec("DELETE FROM task_labels WHERE task_id = ?", taskID).Error; err != nil {
+		return err
+	}
+	for _, id := range labelIDs {
+		if err := tx.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?)", taskID, id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapCustomFieldValues converts the task's custom field values to the
+// fields of the target project with the same name and type. Values the
+// target field does not accept, such as a select option it lacks, are
+// dropped.
+func mapCustomFieldValues(tx *gorm.DB, taskID, fromProjectID, toProjectID uint) ([]models.CustomFieldValue, error) {
+	var values []models.CustomFieldValue
+	if err := tx.Where("task_id = ?", taskID).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var sourceFields, targetFields []models.CustomField
+	if err := tx.Where("project_id = ?", fromProjectID).Find(&sourceFields).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("project_id = ?", toProjectID).Find(&targetFields).Error; err != nil {
+		return nil, err
+	}
+	sourceByID := make(map[uint]*models.CustomField, len(sourceFields))
+	for i := range sourceFields {
+		sourceByID[sourceFields[i].ID] = &sourceFields[i]
+	}
+	targetByName := make(map[string]*models.CustomField, len(targetFields))
+	for i := range targetFields {
+		targetByName[targetFields[i].Name] = &targetFields[i]
+	}
+
+	var mapped []models.CustomFieldValue
+	for i := range values {
+		source, ok := sourceByID[values[i].FieldID]
+		if !ok {
+			continue
+		}
+		target, ok := targetByName[source.Name]
+		if !ok || target.Type != source.Type {
+			continue
+		}
+		raw, err := json.Marshal(customFieldDisplay(source, &values[i]))
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseCustomFieldValue(tx, toProjectID, target, raw)
+		if errors.Is(err, models.ErrCustomFieldValue) || (err == nil && value == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, *value)
+	}
+	return mapped, nil
+}
+
+func createCustomFieldValues(tx *gorm.DB, taskID uint, values []models.CustomFieldValue) error {
+	for i := range values {
+		values[i].TaskID = taskID
+		if err := tx.Create(&values[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropNonMemberAssignee unassigns the checklist item when its assignee is
+// not a member of the project.
+func dropNonMemberAssignee(tx *gorm.DB, item *models.ChecklistItem, projectID uint) error {
+	if item.AssigneeID == nil {
+		return nil
+	}
+	member, err := isProjectMember(tx, projectID, *item.AssigneeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		item.AssigneeID = nil
+	}
+	return nil
+}
+
+// clearChecklistNonMembers unassigns the task's checklist items whose
+// assignees are not members of the project.
+func clearChecklistNonMembers(tx *gorm.DB, taskID, projectID uint) error {
+	var items []models.ChecklistItem
+	if err := tx.Where("task_id = ? AND assignee_id IS NOT NULL", taskID).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := dropNonMemberAssignee(tx, &item, projectID); err != nil {
+			return err
+		}
+		if item.AssigneeID == nil {
+			if err := tx.Model(&item).Update("assignee_id", nil).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyBlob stores a copy of the object under a new key.
+func copyBlob(store storage.Storage, from, to, contentType string) error {
+	body, err := store.Get(context.Background(), from)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	return store.Put(context.Background(), to, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// Move relocates the task into another project. Comments, checklist and
+// attachments go with it, labels and custom field values are mapped by name
+// onto the target project and the sprint is cleared. The task gets a key of
+// the target project; its old key keeps resolving.
+func (s *TaskService) Move(taskID, actorID uint, input TransferTaskInput) (*models.Task, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Labels").First(&task, taskID).Error; err != nil {
+			return err
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+		if err := checkTransfer(tx, &task, input.ProjectID, actorID); err != nil {
+			return err
+		}
+		before := task
+
+		var size int64
+		if err := tx.Model(&models.Attachment{}).Select("COALESCE(SUM(size), 0)").
+			Where("task_id = ?", task.ID).Scan(&size).Error; err != nil {
+			return err
+		}
+		if err := checkAttachmentQuota(tx, input.ProjectID, s.attachmentQuota, size); err != nil {
+			return err
+		}
+		if err := clearChecklistNonMembers(tx, task.ID, input.ProjectID); err != nil {
+			return err
+		}
+
+		labelIDs, err := mapLabels(tx, task.Labels, input.ProjectID)
+		if err != nil {
+			return err
+		}
+		values, err := mapCustomFieldValues(tx, task.ID, task.ProjectID, input.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		if err := addTaskKeyAliases(tx, "id = ?", task.ID); err != nil {
+			return err
+		}
+		number, key, err := models.NextTaskKey(tx, input.ProjectID)
+		if err != nil {
+			return err
+		}
+		task.ProjectID = input.ProjectID
+		task.Number = number
+		task.Key = key
+		task.SprintID = nil
+		task.Version++
+		if err := tx.Model(&task).Select("project_id", "number", "key", "sprint_id", "version").Updates(&task).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Attachment{}).Where("task_id = ?", task.ID).
+			Update("project_id", input.ProjectID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Mention{}).Where("task_id = ?", task.ID).
+			Update("project_id", input.ProjectID).Error; err != nil {
+			return err
+		}
+
+		if err := setTaskLabelIDs(tx, task.ID, labelIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.CustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		if err := createCustomFieldValues(tx, task.ID, values); err != nil {
+			return err
+		}
+
+		if err := recordActivity(tx, &task, actorID, models.TaskActivityMoved, "project_id",
+			uintValue(&before.ProjectID), uintValue(&task.ProjectID)); err != nil {
+			return err
+		}
+		return recordTaskChanges(tx, actorID, &before, &task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(taskID)
+}
+
+// Copy creates a copy of the task in another project together with its
+// comments, checklist and attachments. Labels and custom field values are
+// mapped by name onto the target project; the sprint is not copied.
+func (s *TaskService) Copy(taskID, actorID uint, input TransferTaskInput) (*models.Task, error) {
+	var copied []string
+	var copyID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var source models.Task
+		if err := tx.Preload("Labels").First(&source, taskID).Error; err != nil {
+			return err
+		}
+		if err := checkTransfer(tx, &source, input.ProjectID, actorID); err != nil {
+			return err
+		}
+
+		task := &models.Task{
+			ProjectID:   input.ProjectID,
+			Title:       source.Title,
+			Description: source.Description,
+			Status:      source.Status,
+			Priority:    source.Priority,
+			DueDate:     source.DueDate,
+			AssigneeID:  source.AssigneeID,
+			StoryPoints: source.StoryPoints,
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		copyID = task.ID
+		if err := recordStatusChange(tx, task, "", task.Status); err != nil {
+			return err
+		}
+		if err := recordActivity(tx, task, actorID, models.TaskActivityCreated, "copied_from", nil, stringValue(source.Key)); err != nil {
+			return err
+		}
+		if err := syncMentions(tx, task, nil, actorID, task.Description); err != nil {
+			return err
+		}
+
+		labelIDs, err := mapLabels(tx, source.Labels, input.ProjectID)
+		if err != nil {
+			return err
+		}
+		if err := setTaskLabelIDs(tx, task.ID, labelIDs); err != nil {
+			return err
+		}
+		values, err := mapCustomFieldValues(tx, source.ID, source.ProjectID, input.ProjectID)
+		if err != nil {
+			return err
+		}
+		if err := createCustomFieldValues(tx, task.ID, values); err != nil {
+			return err
+		}
+
+		var items []models.ChecklistItem
+		if err := tx.Where("task_id = ?", source.ID).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := dropNonMemberAssignee(tx, &item, input.ProjectID); err != nil {
+				return err
+			}
+			item.ID = 0
+			item.TaskID = task.ID
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+		}
+
+		var comments []models.Comment
+		if err := tx.Where("task_id = ?", source.ID).Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+			return err
+		}
+		commentIDs := make(map[uint]uint, len(comments))
+		for _, comment := range comments {
+			sourceID := comment.ID
+			if comment.ParentID != nil {
+				// Replies to trashed comments become top-level comments.
+				if parentID, ok := commentIDs[*comment.ParentID]; ok {
+					comment.ParentID = &parentID
+				} else {
+					comment.ParentID = nil
+				}
+			}
+			comment.ID = 0
+			comment.TaskID = task.ID
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			if err := syncMentions(tx, task, &comment.ID, comment.UserID, comment.Text); err != nil {
+				return err
+			}
+			commentIDs[sourceID] = comment.ID
+		}
+
+		var attachments []models.Attachment
+		if err := tx.Where("task_id = ?", source.ID).Order("id ASC").Find(&attachments).Error; err != nil {
+			return err
+		}
+		var size int64
+		kept := attachments[:0]
+		for _, attachment := range attachments {
+			// Attachments of trashed comments are not copied.
+			if attachment.CommentID == nil || commentIDs[*attachment.CommentID] != 0 {
+				kept = append(kept, attachment)
+				size += attachment.Size
+			}
+		}
+		if err := checkAttachmentQuota(tx, input.ProjectID, s.attachmentQuota, size); err != nil {
+			return err
+		}
+		for _, attachment := range kept {
+			var commentID *uint
+			if attachment.CommentID != nil {
+				id := commentIDs[*attachment.CommentID]
+				commentID = &id
+			}
+
+			key, err := newStorageKey(input.ProjectID)
+			if err != nil {
+				return err
+			}
+			if err := copyBlob(s.store, attachment.StorageKey, key, attachment.ContentType); err != nil {
+				return err
+			}
+			copied = append(copied, key)
+
+			// A thumbnail that is still pending stays pending on the copy,
+			// and the thumbnail sweep generates it from the copied file.
+			thumbnailKey := ""
+			if attachment.ThumbnailStatus == models.ThumbnailStatusReady && attachment.ThumbnailKey != "" {
+				thumbnailKey = key + "_thumb"
+				contentType := "image/png"
+				if attachment.ContentType == "image/jpeg" {
+					contentType = attachment.ContentType
+				}
+				if err := copyBlob(s.store, attachment.ThumbnailKey, thumbnailKey, contentType); err != nil {
+					return err
+				}
+				copied = append(copied, thumbnailKey)
+			}
+
+			sourceID := attachment.ID
+			attachment.ID = 0
+			attachment.ProjectID = input.ProjectID
+			attachment.TaskID = task.ID
+			attachment.CommentID = commentID
+			attachment.StorageKey = key
+			attachment.ThumbnailKey = thumbnailKey
+			if err := tx.Create(&attachment).Error; err != nil {
+				return err
+			}
+			if source.CoverAttachmentID != nil && *source.CoverAttachmentID == sourceID {
+				if err := tx.Model(task).Update("cover_attachment_id", attachment.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		removeBlobs(s.store, copied)
+		return nil, err
+	}
+	return s.GetByID(copyID)
+}
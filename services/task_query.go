@@ -0,0 +1,417 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 200
+	maxTaskSortFields   = 5
+)
+
+// TaskFilter narrows a project's task list. Zero values mean "no
+// restriction". With MatchAllLabels a task must carry every label in
+// LabelIDs, otherwise any one of them is enough. Query is an additional
+// query language expression in which "me" means UserID; its ORDER BY is
+// used when Sort is empty.
+type TaskFilter struct {
+	Statuses    []models.TaskStatus
+	Priorities  []models.TaskPriority
+	AssigneeIDs []uint
+	Unassigned  bool
+	DueFrom     time.Time
+	DueTo       time.Time
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Text        string
+
+	LabelIDs       []uint
+	MatchAllLabels bool
+	CustomFields   []CustomFieldCondition
+
+	Query  string
+	UserID uint
+
+	Sort   []TaskSort
+	Cursor string
+	Limit  int
+}
+
+// TaskSort orders tasks by Field, which is one of created_at, updated_at,
+// due_date, title, priority, status, story_points or cf:<field id>.
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// TaskPage is one page of a task listing. NextCursor is empty on the last
+// page; Total counts every matching task.
+type TaskPage struct {
+	Items      []models.Task `json:"items"`
+	Limit      int           `json:"limit"`
+	Total      int64         `json:"total"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+var taskSortColumns = map[string]string{
+	"created_at":   "tasks.created_at",
+	"updated_at":   "tasks.updated_at",
+	"due_date":     "tasks.due_date",
+	"title":        "tasks.title",
+	"priority":     "CASE tasks.priority WHEN 'LOW' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'HIGH' THEN 3 ELSE 0 END",
+	"status":       "CASE tasks.status WHEN 'TODO' THEN 1 WHEN 'IN_PROGRESS' THEN 2 WHEN 'DONE' THEN 3 ELSE 0 END",
+	"story_points": "tasks.story_points",
+}
+
+// taskSortKey is one ORDER BY expression of a listing. Nullable expressions
+// are preceded by an "IS NULL" key so that tasks without a value come last
+// in both directions.
+type taskSortKey struct {
+	expr     string
+	desc     bool
+	join     string
+	joinArgs []interface{}
+}
+
+func (k taskSortKey) orderBy() string {
+	if k.desc {
+		return k.expr + " DESC"
+	}
+	return k.expr + " ASC"
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// filterTasks builds the query selecting the project's tasks that match the
+// filter, without ordering or pagination.
+func (s *TaskService) filterTasks(projectID uint, filter TaskFilter) (*gorm.DB, error) {
+	query := s.db.Model(&models.Task{}).Where("tasks.project_id = ?", projectID)
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("tasks.priority IN ?", filter.Priorities)
+	}
+	switch {
+	case len(filter.AssigneeIDs) > 0 && filter.Unassigned:
+		query = query.Where("(tasks.assignee_id IN ? OR tasks.assignee_id IS NULL)", filter.AssigneeIDs)
+	case len(filter.AssigneeIDs) > 0:
+		query = query.Where("tasks.assignee_id IN ?", filter.AssigneeIDs)
+	case filter.Unassigned:
+		query = query.Where("tasks.assignee_id IS NULL")
+	}
+
+	ranges := []struct {
+		column   string
+		from, to time.Time
+	}{
+		{"tasks.due_date", filter.DueFrom, filter.DueTo},
+		{"tasks.created_at", filter.CreatedFrom, filter.CreatedTo},
+		{"tasks.updated_at", filter.UpdatedFrom, filter.UpdatedTo},
+	}
+	for _, r := range ranges {
+		if !r.from.IsZero() {
+			query = query.Where(r.column+" >= ?", r.from)
+		}
+		if !r.to.IsZero() {
+			query = query.Where(r.column+" <= ?", r.to)
+		}
+	}
+
+	if text := strings.TrimSpace(filter.Text); text != "" {
+		pattern := "%" + escapeLike(text) + "%"
+		query = query.Where("(tasks.title ILIKE ? OR tasks.description ILIKE ?)", pattern, pattern)
+	}
+
+	if len(filter.LabelIDs) > 0 {
+		labelled := s.db.Table("task_labels").Select("task_id").Where("label_id IN ?", filter.LabelIDs)
+		if filter.MatchAllLabels {
+			labelled = labelled.Group("task_id").Having("COUNT(DISTINCT label_id) = ?", len(uniqueIDs(filter.LabelIDs)))
+		}
+		query = query.Where("tasks.id IN (?)", labelled)
+	}
+	for _, cond := range filter.CustomFields {
+		var err error
+		if query, err = applyCustomFieldFilter(s.db, query, projectID, cond); err != nil {
+			return nil, err
+		}
+	}
+	return query, nil
+}
+
+// sortKeys turns the requested sort into ORDER BY keys, always ending with
+// the task ID so that the order is total and usable for keyset pagination.
+func (s *TaskService) sortKeys(projectID uint, sorts []TaskSort) ([]taskSortKey, error) {
+	if len(sorts) > maxTaskSortFields {
+		return nil, models.ErrInvalidSort
+	}
+
+	var keys []taskSortKey
+	seen := make(map[string]bool, len(sorts))
+	for i, sort := range sorts {
+		if seen[sort.Field] {
+			return nil, models.ErrInvalidSort
+		}
+		seen[sort.Field] = true
+
+		if column, ok := taskSortColumns[sort.Field]; ok {
+			if sort.Field == "story_points" {
+				keys = append(keys, taskSortKey{expr: "(" + column + " IS NULL)"})
+			}
+			keys = append(keys, taskSortKey{expr: column, desc: sort.Desc})
+			continue
+		}
+
+		fieldID, err := strconv.ParseUint(strings.TrimPrefix(sort.Field, "cf:"), 10, 32)
+		if err != nil || !strings.HasPrefix(sort.Field, "cf:") {
+			return nil, models.ErrInvalidSort
+		}
+		column, err := customFieldSortColumn(s.db, projectID, uint(fieldID))
+		if err != nil {
+			return nil, err
+		}
+		alias := fmt.Sprintf("sort_value_%d", i)
+		expr := alias + "." + column
+		keys = append(keys,
+			taskSortKey{
+				expr:     "(" + expr + " IS NULL)",
+				join:     fmt.Sprintf("LEFT JOIN custom_field_values AS %s ON %s.task_id = tasks.id AND %s.field_id = ?", alias, alias, alias),
+				joinArgs: []interface{}{uint(fieldID)},
+			},
+			taskSortKey{expr: expr, desc: sort.Desc},
+		)
+	}
+	return append(keys, taskSortKey{expr: "tasks.id"}), nil
+}
+
+// pageTasks sorts the filtered tasks and returns the page after cursor.
+// projectID scopes custom field sorting and is zero across projects.
+func (s *TaskService) pageTasks(query *gorm.DB, projectID uint, sort []TaskSort, cursor string, limit int) (*TaskPage, error) {
+	switch {
+	case limit < 1:
+		limit = defaultTaskPageSize
+	case limit > maxTaskPageSize:
+		limit = maxTaskPageSize
+	}
+	page := &TaskPage{Items: []models.Task{}, Limit: limit}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	keys, err := s.sortKeys(projectID, sort)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.join != "" {
+			query = query.Joins(key.join, key.joinArgs...)
+		}
+	}
+	if cursor != "" {
+		values, err := decodeTaskCursor(cursor, sortSignature(sort), len(keys))
+		if err != nil {
+			return nil, err
+		}
+		condition, args := keysetCondition(keys, values)
+		query = query.Where(condition, args...)
+	}
+	for _, key := range keys {
+		query = query.Order(key.orderBy())
+	}
+
+	var tasks []models.Task
+	if err := query.
+		Select("tasks.*").
+		Preload("Assignee").
+		Preload("Labels").
+		Limit(limit + 1).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		next, err := s.taskCursor(keys, sortSignature(sort), tasks[limit-1].ID)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	if err := attachChecklistSummaries(s.db, tasks); err != nil {
+		return nil, err
+	}
+	if err := attachCustomFieldValues(s.db, tasks); err != nil {
+		return nil, err
+	}
+	page.Items = tasks
+	return page, nil
+}
+
+// keysetCondition selects the rows that come after values in the order
+// given by keys:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(keys []taskSortKey, values []interface{}) (string, []interface{}) {
+	var disjuncts []string
+	var args []interface{}
+	for i, key := range keys {
+		if values[i] == nil {
+			// Nothing sorts after NULL within the same prefix.
+			continue
+		}
+
+		var conjuncts []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				conjuncts = append(conjuncts, keys[j].expr+" IS NULL")
+			} else {
+				conjuncts = append(conjuncts, keys[j].expr+" = ?")
+				args = append(args, values[j])
+			}
+		}
+		op := " > ?"
+		if key.desc {
+			op = " < ?"
+		}
+		conjuncts = append(conjuncts, key.expr+op)
+		args = append(args, values[i])
+		disjuncts = append(disjuncts, "("+strings.Join(conjuncts, " AND ")+")")
+	}
+	if len(disjuncts) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", args
+}
+
+type taskCursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+// taskCursorData is what a cursor encodes: the sort it was issued for and
+// the sort key values of the last task on the page.
+type taskCursorData struct {
+	Sort   string            `json:"s"`
+	Values []taskCursorValue `json:"v"`
+}
+
+// sortSignature names the sort order, such as "priority,-due_date", so a
+// cursor issued for one order is refused under another.
+func sortSignature(sorts []TaskSort) string {
+	fields := make([]string, len(sorts))
+	for i, sort := range sorts {
+		fields[i] = sort.Field
+		if sort.Desc {
+			fields[i] = "-" + sort.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// taskCursor reads the sort key values of the task and encodes them as an
+// opaque cursor.
+func (s *TaskService) taskCursor(keys []taskSortKey, signature string, taskID uint) (string, error) {
+	exprs := make([]string, len(keys))
+	query := s.db.Table("tasks")
+	for i, key := range keys {
+		exprs[i] = key.expr
+		if key.join != "" {
+			query = query.Joins(key.join, key.joinArgs...)
+		}
+	}
+
+	rows, err := query.Select(strings.Join(exprs, ", ")).Where("tasks.id = ?", taskID).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return "", rows.Err()
+	}
+
+	values := make([]interface{}, len(keys))
+	dest := make([]interface{}, len(keys))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", err
+	}
+
+	encoded := make([]taskCursorValue, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			encoded[i] = taskCursorValue{Type: "null"}
+		case time.Time:
+			encoded[i] = taskCursorValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+		case int64:
+			encoded[i] = taskCursorValue{Type: "int", Value: strconv.FormatInt(v, 10)}
+		case float64:
+			encoded[i] = taskCursorValue{Type: "float", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+		case bool:
+			encoded[i] = taskCursorValue{Type: "bool", Value: strconv.FormatBool(v)}
+		case string:
+			encoded[i] = taskCursorValue{Type: "string", Value: v}
+		case []byte:
+			encoded[i] = taskCursorValue{Type: "string", Value: string(v)}
+		default:
+			return "", fmt.Errorf("unsupported cursor value %T", value)
+		}
+	}
+
+	data, err := json.Marshal(taskCursorData{Sort: signature, Values: encoded})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeTaskCursor(cursor, signature string, size int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	var decoded taskCursorData
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Sort != signature || len(decoded.Values) != size {
+		return nil, models.ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(decoded.Values))
+	for i, value := range decoded.Values {
+		var err error
+		switch value.Type {
+		case "null":
+			values[i] = nil
+		case "time":
+			values[i], err = time.Parse(time.RFC3339Nano, value.Value)
+		case "int":
+			values[i], err = strconv.ParseInt(value.Value, 10, 64)
+		case "float":
+			values[i], err = strconv.ParseFloat(value.Value, 64)
+		case "bool":
+			values[i], err = strconv.ParseBool(value.Value)
+		case "string":
+			values[i] = value.Value
+		default:
+			err = models.ErrInvalidCursor
+		}
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"strings"
+	"taskive/models"
+	"taskive/taskql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// taskQueryColumns maps query language fields to task columns.
+var taskQueryColumns = map[string]string{
+	"status":      "tasks.status",
+	"priority":    "tasks.priority",
+	"due":         "tasks.due_date",
+	"created":     "tasks.created_at",
+	"updated":     "tasks.updated_at",
+	"points":      "tasks.story_points",
+	"project":     "tasks.project_id",
+	"sprint":      "tasks.sprint_id",
+	"assignee":    "tasks.assignee_id",
+	"title":       "tasks.title",
+	"description": "tasks.description",
+	"text":        "(tasks.title || ' ' || COALESCE(tasks.description, ''))",
+}
+
+// taskQuerySorts maps query language sort fields to TaskSort fields.
+var taskQuerySorts = map[string]string{
+	"status":   "status",
+	"priority": "priority",
+	"due":      "due_date",
+	"created":  "created_at",
+	"updated":  "updated_at",
+	"points":   "story_points",
+	"title":    "title",
+}
+
+// taskQueryCompiler turns a parsed query into a parameterized SQL condition.
+// Columns and operators come from fixed tables; only values are bound.
+type taskQueryCompiler struct {
+	userID uint
+}
+
+func (c *taskQueryCompiler) compile(expr taskql.Expr) (string, []interface{}) {
+	switch e := expr.(type) {
+	case *taskql.And:
+		return c.join(e.Left, "AND", e.Right)
+	case *taskql.Or:
+		return c.join(e.Left, "OR", e.Right)
+	case *taskql.Not:
+		sql, args := c.compile(e.Expr)
+		return "NOT (" + sql + ")", args
+	case *taskql.Comparison:
+		if e.Type == taskql.FieldLabel {
+			return c.label(e)
+		}
+		return c.comparison(e)
+	}
+	return "TRUE", nil
+}
+
+func (c *taskQueryCompiler) join(left taskql.Expr, op string, right taskql.Expr) (string, []interface{}) {
+	leftSQL, leftArgs := c.compile(left)
+	rightSQL, rightArgs := c.compile(right)
+	return "(" + leftSQL + " " + op + " " + rightSQL + ")", append(leftArgs, rightArgs...)
+}
+
+func (c *taskQueryCompiler) value(cmp *taskql.Comparison, v taskql.Value) interface{} {
+	switch cmp.Type {
+	case taskql.FieldEnum, taskql.FieldText:
+		return v.Text
+	case taskql.FieldTime:
+		return v.Time
+	case taskql.FieldUser:
+		if v.Me {
+			return c.userID
+		}
+		return uint(v.Number)
+	case taskql.FieldID:
+		return uint(v.Number)
+	}
+	return v.Number
+}
+
+func (c *taskQueryCompiler) values(cmp *taskql.Comparison) []interface{} {
+	values := make([]interface{}, len(cmp.Values))
+	for i, v := range cmp.Values {
+		values[i] = c.value(cmp, v)
+	}
+	return values
+}
+
+func (c *taskQueryCompiler) comparison(cmp *taskql.Comparison) (string, []interface{}) {
+	column := taskQueryColumns[cmp.Field]
+	nullable := cmp.Type == taskql.FieldNumber || cmp.Type == taskql.FieldID || cmp.Type == taskql.FieldUser
+
+	switch cmp.Op {
+	case taskql.OpEmpty:
+		return column + " IS NULL", nil
+	case taskql.OpNotEmpty:
+		return column + " IS NOT NULL", nil
+	case taskql.OpIn:
+		return column + " IN ?", []interface{}{c.values(cmp)}
+	case taskql.OpNotIn:
+		if nullable {
+			return "(" + column + " IS NULL OR " + column + " NOT IN ?)", []interface{}{c.values(cmp)}
+		}
+		return column + " NOT IN ?", []interface{}{c.values(cmp)}
+	}
+
+	value := c.value(cmp, cmp.Values[0])
+	if cmp.Type == taskql.FieldText {
+		switch cmp.Op {
+		case taskql.OpEq:
+			return "LOWER(" + column + ") = LOWER(?)", []interface{}{value}
+		case taskql.OpNotEq:
+			return "LOWER(" + column + ") <> LOWER(?)", []interface{}{value}
+		case taskql.OpContains:
+			return column + " ILIKE ?", []interface{}{"%" + escapeLike(cmp.Values[0].Text) + "%"}
+		case taskql.OpNotContain:
+			return column + " NOT ILIKE ?", []interface{}{"%" + escapeLike(cmp.Values[0].Text) + "%"}
+		}
+	}
+	if cmp.Op == taskql.OpNotEq {
+		if nullable {
+			return column + " IS DISTINCT FROM ?", []interface{}{value}
+		}
+		return column + " <> ?", []interface{}{value}
+	}
+	return column + " " + cmp.Op + " ?", []interface{}{value}
+}
+
+// label matches tasks by label name, ignoring case.
+func (c *taskQueryCompiler) label(cmp *taskql.Comparison) (string, []interface{}) {
+	const labelled = "EXISTS (SELECT 1 FROM task_labels JOIN labels ON labels.id = task_labels.label_id WHERE task_labels.task_id = tasks.id"
+
+	switch cmp.Op {
+	case taskql.OpEmpty:
+		return "NOT " + labelled + ")", nil
+	case taskql.OpNotEmpty:
+		return labelled + ")", nil
+	}
+
+	names := make([]string, len(cmp.Values))
+	for i, v := range cmp.Values {
+		names[i] = strings.ToLower(v.Text)
+	}
+	condition := labelled + " AND LOWER(labels.name) IN ?)"
+	if cmp.Op == taskql.OpNotEq || cmp.Op == taskql.OpNotIn {
+		condition = "NOT " + condition
+	}
+	return condition, []interface{}{names}
+}
+
+// applyTaskQuery parses q, adds its condition to query and returns the
+// sort given by its ORDER BY clause. userID resolves "me".
+func applyTaskQuery(query *gorm.DB, q string, userID uint) (*gorm.DB, []TaskSort, error) {
+	parsed, err := taskql.Parse(q, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parsed.Where != nil {
+		compiler := &taskQueryCompiler{userID: userID}
+		condition, args := compiler.compile(parsed.Where)
+		query = query.Where(condition, args...)
+	}
+
+	sort := make([]TaskSort, len(parsed.OrderBy))
+	for i, item := range parsed.OrderBy {
+		sort[i] = TaskSort{Field: taskQuerySorts[item.Field], Desc: item.Desc}
+	}
+	return query, sort, nil
+}
+
+// Search runs a task query language expression over every project the user
+// is an accepted member of. Without ORDER BY tasks come in creation order.
+func (s *TaskService) Search(userID uint, q, cursor string, limit int) (*TaskPage, error) {
+	accessible := s.db.Model(&models.Member{}).
+		Select("project_id").
+		Where("user_id = ? AND status = ?", userID, models.MemberStatusAccepted)
+	query := s.db.Model(&models.Task{}).Where("tasks.project_id IN (?)", accessible)
+
+	query, sort, err := applyTaskQuery(query, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pageTasks(query, 0, sort, cursor, limit)
+}
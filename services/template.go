@@ -0,0 +1,354 @@
+package services
+
+import (
+	"encoding/json"
+	"taskive/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TemplateService struct {
+	db *gorm.DB
+}
+
+func NewTemplateService(db *gorm.DB) *TemplateService {
+	return &TemplateService{db: db}
+}
+
+type CreateTemplateInput struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// dayOffset returns the number of calendar days from start to t, or nil
+// when t is not set.
+func dayOffset(start, t time.Time) *int {
+	if t.IsZero() {
+		return nil
+	}
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(to.Sub(from).Hours() / 24)
+	return &days
+}
+
+// shiftDate returns the date offset days after start, or the zero time
+// when there is no offset.
+func shiftDate(start time.Time, offset *int) time.Time {
+	if offset == nil {
+		return time.Time{}
+	}
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	return day.AddDate(0, 0, *offset)
+}
+
+// snapshotProject captures the labels, custom fields, tasks and checklists
+// of a project. Assignees, sprints, progress and user field values are left
+// out as they do not carry over to a new project.
+func snapshotProject(tx *gorm.DB, projectID uint) (models.TemplateContent, error) {
+	var content models.TemplateContent
+
+	var project models.Project
+	if err := tx.First(&project, projectID).Error; err != nil {
+		return content, err
+	}
+
+	var labels []models.Label
+	if err := tx.Where("project_id = ?", projectID).Order("name ASC").Find(&labels).Error; err != nil {
+		return content, err
+	}
+	for _, label := range labels {
+		content.Labels = append(content.Labels, models.TemplateLabel{
+			Name:        label.Name,
+			Color:       label.Color,
+			Description: label.Description,
+		})
+	}
+
+	var fields []models.CustomField
+	if err := tx.Where("project_id = ?", projectID).Order("position ASC, id ASC").Find(&fields).Error; err != nil {
+		return content, err
+	}
+	fieldsByID := make(map[uint]*models.CustomField, len(fields))
+	for i, field := range fields {
+		fieldsByID[field.ID] = &fields[i]
+		content.CustomFields = append(content.CustomFields, models.TemplateCustomField{
+			Name:     field.Name,
+			Type:     field.Type,
+			Options:  field.Options,
+			Required: field.Required,
+		})
+	}
+
+	var tasks []models.Task
+	if err := tx.Where("project_id = ?", projectID).
+		Preload("Labels").
+		Order("created_at ASC, id ASC").
+		Find(&tasks).Error; err != nil {
+		return content, err
+	}
+	if len(tasks) == 0 {
+		return content, nil
+	}
+	taskIDs := make([]uint, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+
+	var values []models.CustomFieldValue
+	if err := tx.Where("task_id IN ?", taskIDs).Find(&values).Error; err != nil {
+		return content, err
+	}
+	valuesByTask := map[uint]map[string]json.RawMessage{}
+	for i, value := range values {
+		field, ok := fieldsByID[value.FieldID]
+		if !ok || field.Type == models.CustomFieldUser {
+			continue
+		}
+		data, err := json.Marshal(customFieldDisplay(field, &values[i]))
+		if err != nil {
+			return content, err
+		}
+		if valuesByTask[value.TaskID] == nil {
+			valuesByTask[value.TaskID] = map[string]json.RawMessage{}
+		}
+		valuesByTask[value.TaskID][field.Name] = data
+	}
+
+	var items []models.ChecklistItem
+	if err := tx.Where("task_id IN ?", taskIDs).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+		return content, err
+	}
+	itemsByTask := map[uint][]models.TemplateChecklistItem{}
+	for _, item := range items {
+		var due time.Time
+		if item.DueDate != nil {
+			due = *item.DueDate
+		}
+		itemsByTask[item.TaskID] = append(itemsByTask[item.TaskID], models.TemplateChecklistItem{
+			Text:          item.Text,
+			DueOffsetDays: dayOffset(project.StartDate, due),
+		})
+	}
+
+	for _, task := range tasks {
+		entry := models.TemplateTask{
+			Title:         task.Title,
+			Description:   task.Description,
+			Priority:      task.Priority,
+			StoryPoints:   task.StoryPoints,
+			DueOffsetDays: dayOffset(project.StartDate, task.DueDate),
+			CustomFields:  valuesByTask[task.ID],
+			Checklist:     itemsByTask[task.ID],
+		}
+		for _, label := range task.Labels {
+			entry.Labels = append(entry.Labels, label.Name)
+		}
+		content.Tasks = append(content.Tasks, entry)
+	}
+	return content, nil
+}
+
+// applyTemplate fills a new project with the template content. Tasks start
+// as TODO and due dates are shifted to the project's start date.
+func applyTemplate(tx *gorm.DB, project *models.Project, actorID uint, content models.TemplateContent) error {
+	labelIDs := make(map[string]uint, len(content.Labels))
+	for _, entry := range content.Labels {
+		name, err := labelName(entry.Name)
+		if err != nil {
+			// Blank labels are not carried over.
+			continue
+		}
+		label := &models.Label{
+			ProjectID:   project.ID,
+			Name:        name,
+			Color:       entry.Color,
+			Description: entry.Description,
+		}
+		if err := tx.Create(label).Error; err != nil {
+			return err
+		}
+		labelIDs[entry.Name] = label.ID
+	}
+
+	fields := make(map[string]*models.CustomField, len(content.CustomFields))
+	for i, entry := range content.CustomFields {
+		field := &models.CustomField{
+			ProjectID: project.ID,
+			Name:      entry.Name,
+			Type:      entry.Type,
+			Options:   entry.Options,
+			Required:  entry.Required,
+			Position:  i,
+		}
+		if err := tx.Create(field).Error; err != nil {
+			return err
+		}
+		fields[field.Name] = field
+	}
+
+	for _, entry := range content.Tasks {
+		task := &models.Task{
+			ProjectID:   project.ID,
+			Title:       entry.Title,
+			Description: entry.Description,
+			Status:      models.TaskStatusTodo,
+			Priority:    entry.Priority,
+			DueDate:     shiftDate(project.StartDate, entry.DueOffsetDays),
+			StoryPoints: entry.StoryPoints,
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, task, "", task.Status); err != nil {
+			return err
+		}
+		if err := recordActivity(tx, task, actorID, models.TaskActivityCreated, "", nil, nil); err != nil {
+			return err
+		}
+
+		for _, name := range entry.Labels {
+			if id, ok := labelIDs[name]; ok {
+				if err := tx.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?)", task.ID, id).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for name, raw := range entry.CustomFields {
+			field, ok := fields[name]
+			if !ok {
+				continue
+			}
+			value, err := parseCustomFieldValue(tx, project.ID, field, raw)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				continue
+			}
+			value.TaskID = task.ID
+			if err := tx.Create(value).Error; err != nil {
+				return err
+			}
+		}
+
+		for position, item := range entry.Checklist {
+			checklistItem := &models.ChecklistItem{
+				TaskID:   task.ID,
+				Position: position,
+				Text:     item.Text,
+			}
+			if item.DueOffsetDays != nil {
+				due := shiftDate(project.StartDate, item.DueOffsetDays)
+				checklistItem.DueDate = &due
+			}
+			if err := tx.Create(checklistItem).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *TemplateService) GetUserTemplates(userID uint) ([]models.ProjectTemplate, error) {
+	var templates []models.ProjectTemplate
+	err := s.db.Where("owner_id = ?", userID).
+		Order("name ASC").
+		Find(&templates).Error
+	return templates, err
+}
+
+// GetByID returns the template when it belongs to the user.
+func (s *TemplateService) GetByID(templateID, userID uint) (*models.ProjectTemplate, error) {
+	var template models.ProjectTemplate
+	if err := s.db.Preload("Owner").
+		Where("owner_id = ?", userID).
+		First(&template, templateID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateFromProject saves the structure of a project the user is a member
+// of as a new template.
+func (s *TemplateService) CreateFromProject(projectID, userID uint, input CreateTemplateInput) (*models.ProjectTemplate, error) {
+	if err := checkMember(s.db, projectID, userID); err != nil {
+		return nil, err
+	}
+
+	content, err := snapshotProject(s.db, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.ProjectTemplate{
+		Name:        input.Name,
+		Description: input.Description,
+		OwnerID:     userID,
+		Content:     content,
+	}
+	if err := s.db.Create(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *TemplateService) Delete(templateID, userID uint) error {
+	result := s.db.Where("owner_id = ?", userID).Delete(&models.ProjectTemplate{}, templateID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// CreateProject creates a new project owned by the user from the template.
+func (s *TemplateService) CreateProject(templateID, userID uint, input CreateProjectInput) (*models.Project, error) {
+	template, err := s.GetByID(templateID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var project *models.Project
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if project, err = createProject(tx, userID, input); err != nil {
+			return err
+		}
+		return applyTemplate(tx, project, userID, template.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// CloneProject copies the structure of a project the user is a member of
+// into a new project owned by the user.
+func (s *TemplateService) CloneProject(projectID, userID uint, input CreateProjectInput) (*models.Project, error) {
+	if err := checkMember(s.db, projectID, userID); err != nil {
+		return nil, err
+	}
+
+	var project *models.Project
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		content, err := snapshotProject(tx, projectID)
+		if err != nil {
+			return err
+		}
+		if project, err = createProject(tx, userID, input); err != nil {
+			return err
+		}
+		return applyTemplate(tx, project, userID, content)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"taskive/models"
+	"time"
+)
+
+const (
+	thumbnailSize      = 256
+	thumbnailMaxPixels = 40_000_000
+	thumbnailWorkers   = 2
+	thumbnailQueueSize = 100
+
+	thumbnailSweepInterval = 10 * time.Minute
+)
+
+// startThumbnailWorkers processes queued attachment IDs in the background.
+// Attachments still pending from before a restart, or dropped from a full
+// queue, are picked up by a sweep at startup and then every few minutes.
+func (s *AttachmentService) startThumbnailWorkers() {
+	s.thumbnails = make(chan uint, thumbnailQueueSize)
+	for i := 0; i < thumbnailWorkers; i++ {
+		go func() {
+			for id := range s.thumbnails {
+				s.processThumbnail(id)
+			}
+		}()
+	}
+	go func() {
+		for {
+			s.sweepPendingThumbnails()
+			time.Sleep(thumbnailSweepInterval)
+		}
+	}()
+}
+
+// queueThumbnail schedules thumbnail generation without blocking the upload.
+// When the queue is full the attachment stays pending for the next sweep.
+func (s *AttachmentService) queueThumbnail(attachmentID uint) {
+	select {
+	case s.thumbnails <- attachmentID:
+	default:
+	}
+}
+
+// sweepPendingThumbnails queues attachments whose thumbnail is still
+// pending, waiting for room in the queue.
+func (s *AttachmentService) sweepPendingThumbnails() {
+	var ids []uint
+	if err := s.db.Model(&models.Attachment{}).
+		Where("thumbnail_status = ?", models.ThumbnailStatusPending).
+		Order("id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		serviceLogger.LogError("STORAGE", fmt.Sprintf("finding pending thumbnails failed: %v", err))
+		return
+	}
+	for _, id := range ids {
+		s.thumbnails <- id
+	}
+}
+
+func (s *AttachmentService) processThumbnail(attachmentID uint) {
+	var attachment models.Attachment
+	if err := s.db.First(&attachment, attachmentID).Error; err != nil {
+		// The attachment may have been deleted in the meantime.
+		return
+	}
+	if attachment.ThumbnailStatus != models.ThumbnailStatusPending {
+		// Queued twice, by an upload and by a sweep.
+		return
+	}
+
+	status := models.ThumbnailStatusReady
+	key := attachment.StorageKey + "_thumb"
+	if err := s.generateThumbnail(&attachment, key); err != nil {
+		serviceLogger.LogWarning("STORAGE", fmt.Sprintf("thumbnail for attachment %d failed: %v", attachment.ID, err))
+		status, key = models.ThumbnailStatusFailed, ""
+	}
+
+	result := s.db.Model(&models.Attachment{}).Where("id = ?", attachment.ID).Updates(map[string]interface{}{
+		"thumbnail_status": status,
+		"thumbnail_key":    key,
+	})
+	if result.Error == nil && result.RowsAffected == 0 && key != "" {
+		// Deleted while we were working; do not leave the thumbnail behind.
+		removeBlobs(s.store, []string{key})
+	}
+}
+
+func (s *AttachmentService) generateThumbnail(attachment *models.Attachment, key string) error {
+	body, err := s.store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if config.Width*config.Height > thumbnailMaxPixels {
+		return fmt.Errorf("image too large: %dx%d", config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	thumb := scaleToFit(src, thumbnailSize)
+
+	var buf bytes.Buffer
+	contentType := attachment.ContentType
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+	} else {
+		// GIF thumbnails are stored as PNG of the first frame.
+		contentType = "image/png"
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.store.Put(context.Background(), key, &buf, int64(buf.Len()), contentType)
+}
+
+// scaleToFit shrinks src so that neither side exceeds max, keeping the
+// aspect ratio. Each output pixel averages the source pixels it covers.
+func scaleToFit(src image.Image, max int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= max && h <= max {
+		return src
+	}
+
+	dw, dh := max, h*max/w
+	if h > w {
+		dw, dh = w*max/h, max
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := bounds.Min.Y + y*h/dh
+		y1 := bounds.Min.Y + (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0 := bounds.Min.X + x*w/dw
+			x1 := bounds.Min.X + (x+1)*w/dw
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"taskive/models"
+	"taskive/storage"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const trashPurgeInterval = time.Hour
+
+// TrashService lists and restores soft-deleted projects, tasks and comments,
+// and purges them for good once the retention period has passed.
+type TrashService struct {
+	db        *gorm.DB
+	store     storage.Storage
+	retention time.Duration
+}
+
+func NewTrashService(db *gorm.DB, store storage.Storage, retention time.Duration) *TrashService {
+	return &TrashService{db: db, store: store, retention: retention}
+}
+
+// ProjectTrash holds the trashed content of a project. Comments trashed
+// together with their task are restored with it and are not listed.
+type ProjectTrash struct {
+	Tasks         []models.Task    `json:"tasks"`
+	Comments      []models.Comment `json:"comments"`
+	RetentionDays int              `json:"retention_days"`
+}
+
+func (s *TrashService) GetProjectTrash(projectID, userID uint) (*ProjectTrash, error) {
+	member, err := isProjectMember(s.db, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, models.ErrForbidden
+	}
+
+	trash := &ProjectTrash{RetentionDays: int(s.retention.Hours() / 24)}
+	if err := s.db.Unscoped().
+		Where("project_id = ? AND deleted_at IS NOT NULL", projectID).
+		Order("deleted_at DESC").
+		Find(&trash.Tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Unscoped().
+		Joins("JOIN tasks ON tasks.id = comments.task_id").
+		Where("tasks.project_id = ? AND tasks.deleted_at IS NULL AND comments.deleted_at IS NOT NULL", projectID).
+		Preload("User").
+		Order("comments.deleted_at DESC").
+		Find(&trash.Comments).Error; err != nil {
+		return nil, err
+	}
+	return trash, nil
+}
+
+// GetTrashedProjects lists the trashed projects the user owns.
+func (s *TrashService) GetTrashedProjects(userID uint) ([]models.Project, error) {
+	var projects []models.Project
+	err := s.db.Unscoped().
+		Joins("JOIN project_members ON projects.id = project_members.project_id").
+		Where("project_members.user_id = ? AND project_members.role = ? AND project_members.status = ?",
+			userID, models.MemberRoleOwner, models.MemberStatusAccepted).
+		Where("projects.deleted_at IS NOT NULL").
+		Order("projects.deleted_at DESC").
+		Find(&projects).Error
+	return projects, err
+}
+
+// RestoreProject brings back a trashed project with the tasks and comments
+// that were trashed along with it.
+func (s *TrashService) RestoreProject(projectID uint, meta AuditMeta) (*models.Project, error) {
+	var project models.Project
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&project, projectID).Error; err != nil {
+			return err
+		}
+		if !project.DeletedAt.Valid {
+			return models.ErrNotInTrash
+		}
+		deletedAt := project.DeletedAt.Time
+
+		projectTasks := tx.Unscoped().Model(&models.Task{}).Select("id").Where("project_id = ?", projectID)
+		if err := tx.Unscoped().Model(&models.Comment{}).
+			Where("task_id IN (?) AND deleted_at = ?", projectTasks, deletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Task{}).
+			Where("project_id = ? AND deleted_at = ?", projectID, deletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&project).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, meta, models.AuditActionProjectRestore, models.AuditTargetProject, project.ID, &project.ID, nil, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// RestoreTask brings back a trashed task with the comments that were
+// trashed along with it. The project must not be in the trash.
+func (s *TrashService) RestoreTask(taskID, actorID uint) (*models.Task, error) {
+	var task models.Task
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&task, taskID).Error; err != nil {
+			return err
+		}
+		if !task.DeletedAt.Valid {
+			return models.ErrNotInTrash
+		}
+		if err := checkMember(tx, task.ProjectID, actorID); err != nil {
+			return err
+		}
+		if err := tx.First(&models.Project{}, task.ProjectID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrParentTrashed
+			}
+			return err
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Comment{}).
+			Where("task_id = ? AND deleted_at = ?", taskID, task.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&task).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		title := task.Title
+		return recordActivity(tx, &task, actorID, models.TaskActivityRestored, "title", nil, &title)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// RestoreComment brings back a trashed comment. Its task must not be in the
+// trash.
+func (s *TrashService) RestoreComment(commentID, userID uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&comment, commentID).Error; err != nil {
+			return err
+		}
+		if !comment.DeletedAt.Valid {
+			return models.ErrNotInTrash
+		}
+		var task models.Task
+		if err := tx.First(&task, comment.TaskID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrParentTrashed
+			}
+			return err
+		}
+		if err := checkMember(tx, task.ProjectID, userID); err != nil {
+			return err
+		}
+		if err := checkProjectWritable(tx, task.ProjectID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&comment).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("User").Preload("Mentions.User").First(&comment, comment.ID).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func checkMember(tx *gorm.DB, projectID, userID uint) error {
+	member, err := isProjectMember(tx, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// StartPurgeJob purges expired trash now and then every hour. A retention
+// of zero or less keeps trashed items forever.
+func (s *TrashService) StartPurgeJob() {
+	if s.retention <= 0 {
+		serviceLogger.LogInfo("TRASH", "trash retention is not positive, purging is disabled")
+		return
+	}
+	go func() {
+		for {
+			if err := s.Purge(time.Now()); err != nil {
+				serviceLogger.LogError("TRASH", fmt.Sprintf("purging trash failed: %v", err))
+			}
+			time.Sleep(trashPurgeInterval)
+		}
+	}()
+}
+
+// Purge permanently removes projects, tasks and comments trashed longer
+// than the retention period before now.
+func (s *TrashService) Purge(now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.retention)
+	purged := 0
+
+	kinds := []struct {
+		model interface{}
+		// keep excludes rows that must stay even though they expired.
+		keep  string
+		purge func(tx *gorm.DB, id uint) ([]string, error)
+	}{
+		{&models.Project{}, "", purgeProject},
+		{&models.Task{}, "", purgeTask},
+		// A comment with replies stays as their deleted placeholder until
+		// the replies are gone.
+		{&models.Comment{}, "EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id)", purgeComment},
+	}
+	for _, kind := range kinds {
+		query := s.db.Unscoped().Model(kind.model).Where("deleted_at < ?", cutoff)
+		if kind.keep != "" {
+			query = query.Where("NOT " + kind.keep)
+		}
+		var ids []uint
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			var blobs []string
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				keys, err := kind.purge(tx, id)
+				blobs = keys
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			removeBlobs(s.store, blobs)
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		serviceLogger.LogInfo("TRASH", fmt.Sprintf("purged %d trashed items", purged))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"taskive/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxVersionRetries bounds how often an unconditional write is redone
+// after losing a race with another writer.
+const maxVersionRetries = 3
+
+// saveVersioned writes every column of model, which must carry its new
+// version already. The write only happens while the stored version still
+// equals read, the version the caller loaded the row at; otherwise
+// ErrVersionConflict is returned.
+func saveVersioned(tx *gorm.DB, model interface{}, read int, omit ...string) error {
+	result := tx.Model(model).Select("*").Omit(append(omit, clause.Associations)...).
+		Where("version = ?", read).
+		Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrVersionConflict
+	}
+	return nil
+}
+
+// retryUnconditional redoes write, which reads the row afresh, when it lost
+// a race with another writer although the caller gave no version. Writes
+// conditional on a version fail with ErrVersionConflict right away.
+func retryUnconditional(version int, write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err != models.ErrVersionConflict || version != 0 || attempt == maxVersionRetries {
+			return err
+		}
+	}
+}
+
+// touchTask bumps the version of a task whose representation changed
+// through a related record, such as a label or a checklist item.
+func touchTask(tx *gorm.DB, taskID uint) error {
+	return tx.Model(&models.Task{}).Where("id = ?", taskID).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+// touchTasks is touchTask for every task, trashed ones included, matching
+// the conditions.
+func touchTasks(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	return tx.Unscoped().Model(&models.Task{}).Where(query, args...).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStorage keeps objects in memory. It is meant for tests and local
+// experiments, not for production use.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: map[string][]byte{}}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.objects[key] = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	data, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
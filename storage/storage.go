@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage keeps attachment blobs. Keys are slash separated relative paths
+// chosen by the caller, e.g. "projects/1/attachments/ab12".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
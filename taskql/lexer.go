@@ -0,0 +1,179 @@
+// Package taskql parses the task query language used by task search, e.g.
+//
+//	status != DONE AND priority = HIGH AND due < now()+7d AND assignee in (me, 12) ORDER BY due
+package taskql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenDuration
+	tokenOp
+	tokenPlus
+	tokenMinus
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenIdent:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenDate:
+		return "date"
+	case tokenDuration:
+		return "duration"
+	case tokenOp:
+		return "operator"
+	case tokenPlus:
+		return "'+'"
+	case tokenMinus:
+		return "'-'"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenComma:
+		return "','"
+	}
+	return "token"
+}
+
+// token is a lexical token. Pos is the 1-based character offset of its
+// first character in the query.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// SyntaxError reports a problem in the query and where it was found.
+type SyntaxError struct {
+	Pos int    `json:"position"`
+	Msg string `json:"message"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+func errorAt(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+// lex splits the query into tokens.
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", pos})
+			i++
+		case r == '+':
+			tokens = append(tokens, token{tokenPlus, "+", pos})
+			i++
+		case r == '-':
+			tokens = append(tokens, token{tokenMinus, "-", pos})
+			i++
+
+		case r == '=' || r == '~':
+			tokens = append(tokens, token{tokenOp, string(r), pos})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '!' && runes[i+1] == '~')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, errorAt(pos, "unexpected '!', did you mean '!=' or '!~'?")
+			}
+			tokens = append(tokens, token{tokenOp, op, pos})
+			i += len([]rune(op))
+
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, errorAt(pos, "unterminated string")
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), pos})
+			i = j + 1
+
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			kind := tokenNumber
+			switch {
+			case j-i == 4 && j < len(runes) && runes[j] == '-':
+				// A date such as 2026-01-31.
+				for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '-') {
+					j++
+				}
+				kind = tokenDate
+			case j < len(runes) && unicode.IsLetter(runes[j]):
+				for j < len(runes) && unicode.IsLetter(runes[j]) {
+					j++
+				}
+				kind = tokenDuration
+			}
+			tokens = append(tokens, token{kind, string(runes[i:j]), pos})
+			i = j
+
+		case isIdentRune(r):
+			j := i
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[i:j]), pos})
+			i = j
+
+		default:
+			return nil, errorAt(pos, "unexpected character %q", r)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes) + 1}), nil
+}
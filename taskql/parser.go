@@ -0,0 +1,483 @@
+package taskql
+
+import (
+	"strconv"
+	"strings"
+	"taskive/models"
+	"time"
+)
+
+// FieldType decides which operators and values a field accepts.
+type FieldType int
+
+const (
+	FieldEnum FieldType = iota
+	FieldTime
+	FieldNumber
+	FieldID
+	FieldUser
+	FieldText
+	FieldLabel
+)
+
+// Fields lists the fields a query can use.
+var Fields = map[string]FieldType{
+	"status":      FieldEnum,
+	"priority":    FieldEnum,
+	"due":         FieldTime,
+	"created":     FieldTime,
+	"updated":     FieldTime,
+	"points":      FieldNumber,
+	"project":     FieldID,
+	"sprint":      FieldID,
+	"assignee":    FieldUser,
+	"title":       FieldText,
+	"description": FieldText,
+	"text":        FieldText,
+	"label":       FieldLabel,
+}
+
+// SortFields lists the fields usable in ORDER BY.
+var SortFields = map[string]bool{
+	"status":   true,
+	"priority": true,
+	"due":      true,
+	"created":  true,
+	"updated":  true,
+	"points":   true,
+	"title":    true,
+}
+
+var enumValues = map[string][]string{
+	"status":   {string(models.TaskStatusTodo), string(models.TaskStatusInProgress), string(models.TaskStatusDone)},
+	"priority": {string(models.TaskPriorityLow), string(models.TaskPriorityMedium), string(models.TaskPriorityHigh)},
+}
+
+const (
+	OpEq         = "="
+	OpNotEq      = "!="
+	OpLess       = "<"
+	OpLessEq     = "<="
+	OpGreater    = ">"
+	OpGreaterEq  = ">="
+	OpContains   = "~"
+	OpNotContain = "!~"
+	OpIn         = "IN"
+	OpNotIn      = "NOT IN"
+	OpEmpty      = "IS EMPTY"
+	OpNotEmpty   = "IS NOT EMPTY"
+)
+
+var fieldOps = map[FieldType][]string{
+	FieldEnum:   {OpEq, OpNotEq, OpIn, OpNotIn},
+	FieldTime:   {OpEq, OpNotEq, OpLess, OpLessEq, OpGreater, OpGreaterEq},
+	FieldNumber: {OpEq, OpNotEq, OpLess, OpLessEq, OpGreater, OpGreaterEq, OpIn, OpNotIn, OpEmpty, OpNotEmpty},
+	FieldID:     {OpEq, OpNotEq, OpIn, OpNotIn, OpEmpty, OpNotEmpty},
+	FieldUser:   {OpEq, OpNotEq, OpIn, OpNotIn, OpEmpty, OpNotEmpty},
+	FieldText:   {OpEq, OpNotEq, OpContains, OpNotContain},
+	FieldLabel:  {OpEq, OpNotEq, OpIn, OpNotIn, OpEmpty, OpNotEmpty},
+}
+
+// Query is a parsed query. Where is nil when the query has no condition.
+type Query struct {
+	Where   Expr
+	OrderBy []OrderItem
+}
+
+type OrderItem struct {
+	Field string
+	Desc  bool
+}
+
+// Expr is a boolean expression: *And, *Or, *Not or *Comparison.
+type Expr interface {
+	expr()
+}
+
+type And struct{ Left, Right Expr }
+type Or struct{ Left, Right Expr }
+type Not struct{ Expr Expr }
+
+// Comparison tests one field. Values holds one value for binary operators,
+// the list for IN and NOT IN, and nothing for IS [NOT] EMPTY.
+type Comparison struct {
+	Field  string
+	Type   FieldType
+	Op     string
+	Values []Value
+}
+
+func (*And) expr()        {}
+func (*Or) expr()         {}
+func (*Not) expr()        {}
+func (*Comparison) expr() {}
+
+// Value is a checked comparison operand. Exactly one of the fields is
+// meaningful, depending on the field type: Text for enums, text and labels,
+// Number for numbers and IDs, Time for times, and Me or Number for users.
+type Value struct {
+	Text   string
+	Number float64
+	Time   time.Time
+	Me     bool
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+// Parse parses the query. now is used to evaluate now() and today().
+func Parse(input string, now time.Time) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, now: now}
+
+	query := &Query{}
+	if !p.peekKeyword("ORDER") && p.peek().kind != tokenEOF {
+		if query.Where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekKeyword("ORDER") {
+		p.next()
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if query.OrderBy, err = p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorAt(tok.pos, "unexpected %s %q, expected AND, OR or ORDER BY", tok.kind, tok.text)
+	}
+	return query, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		tok := p.peek()
+		return errorAt(tok.pos, "expected %s, found %s", keyword, describe(tok))
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.peek()
+	if tok.kind != kind {
+		return tok, errorAt(tok.pos, "expected %s, found %s", kind, describe(tok))
+	}
+	return p.next(), nil
+}
+
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return tok.kind.String()
+	}
+	return tok.kind.String() + " " + strconv.Quote(tok.text)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.peekKeyword("NOT") {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokenIdent {
+		return nil, errorAt(fieldTok.pos, "expected a field name, found %s", describe(fieldTok))
+	}
+	name := strings.ToLower(fieldTok.text)
+	fieldType, ok := Fields[name]
+	if !ok {
+		return nil, errorAt(fieldTok.pos, "unknown field %q", fieldTok.text)
+	}
+
+	cmp := &Comparison{Field: name, Type: fieldType}
+	opTok := p.peek()
+	switch {
+	case opTok.kind == tokenOp:
+		p.next()
+		cmp.Op = opTok.text
+	case p.peekKeyword("IN"):
+		p.next()
+		cmp.Op = OpIn
+	case p.peekKeyword("NOT"):
+		p.next()
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		cmp.Op = OpNotIn
+	case p.peekKeyword("IS"):
+		p.next()
+		cmp.Op = OpEmpty
+		if p.peekKeyword("NOT") {
+			p.next()
+			cmp.Op = OpNotEmpty
+		}
+		if err := p.expectKeyword("EMPTY"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errorAt(opTok.pos, "expected an operator after %s, found %s", name, describe(opTok))
+	}
+
+	if !allowedOp(fieldType, cmp.Op) {
+		return nil, errorAt(opTok.pos, "operator %s cannot be used with %s", cmp.Op, name)
+	}
+
+	switch cmp.Op {
+	case OpEmpty, OpNotEmpty:
+	case OpIn, OpNotIn:
+		if _, err := p.expect(tokenLParen); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseValue(name, fieldType)
+			if err != nil {
+				return nil, err
+			}
+			cmp.Values = append(cmp.Values, value)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+	default:
+		value, err := p.parseValue(name, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = []Value{value}
+	}
+	return cmp, nil
+}
+
+func allowedOp(fieldType FieldType, op string) bool {
+	for _, allowed := range fieldOps[fieldType] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue reads one operand and checks it against the field type.
+func (p *parser) parseValue(field string, fieldType FieldType) (Value, error) {
+	tok := p.next()
+	switch fieldType {
+	case FieldEnum:
+		if tok.kind == tokenIdent || tok.kind == tokenString {
+			text := strings.ToUpper(tok.text)
+			for _, allowed := range enumValues[field] {
+				if allowed == text {
+					return Value{Text: text}, nil
+				}
+			}
+			return Value{}, errorAt(tok.pos, "invalid %s %q, expected one of %s", field, tok.text, strings.Join(enumValues[field], ", "))
+		}
+
+	case FieldText, FieldLabel:
+		if tok.kind == tokenString || tok.kind == tokenIdent || tok.kind == tokenNumber {
+			return Value{Text: tok.text}, nil
+		}
+
+	case FieldNumber, FieldID:
+		if tok.kind == tokenNumber {
+			n, err := strconv.ParseFloat(tok.text, 64)
+			if err != nil {
+				return Value{}, errorAt(tok.pos, "invalid number %q", tok.text)
+			}
+			return Value{Number: n}, nil
+		}
+
+	case FieldUser:
+		if tok.kind == tokenIdent && strings.EqualFold(tok.text, "me") {
+			return Value{Me: true}, nil
+		}
+		if tok.kind == tokenNumber {
+			n, err := strconv.ParseUint(tok.text, 10, 32)
+			if err != nil {
+				return Value{}, errorAt(tok.pos, "invalid user ID %q", tok.text)
+			}
+			return Value{Number: float64(n)}, nil
+		}
+
+	case FieldTime:
+		return p.parseTime(tok)
+	}
+	return Value{}, errorAt(tok.pos, "invalid value %s for %s", describe(tok), field)
+}
+
+// parseTime reads a date (2026-01-31), a quoted RFC 3339 timestamp or
+// now()/today() optionally shifted by a duration such as +7d or -2w.
+func (p *parser) parseTime(tok token) (Value, error) {
+	switch tok.kind {
+	case tokenDate, tokenString:
+		if t, err := time.Parse("2006-01-02", tok.text); err == nil {
+			return Value{Time: t}, nil
+		}
+		if t, err := time.Parse(time.RFC3339, tok.text); err == nil {
+			return Value{Time: t}, nil
+		}
+		return Value{}, errorAt(tok.pos, "invalid date %q, expected YYYY-MM-DD", tok.text)
+
+	case tokenIdent:
+		var base time.Time
+		switch strings.ToLower(tok.text) {
+		case "now":
+			base = p.now
+		case "today":
+			base = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+		default:
+			return Value{}, errorAt(tok.pos, "unknown function %q, expected now() or today()", tok.text)
+		}
+		if _, err := p.expect(tokenLParen); err != nil {
+			return Value{}, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return Value{}, err
+		}
+
+		sign := p.peek()
+		if sign.kind != tokenPlus && sign.kind != tokenMinus {
+			return Value{Time: base}, nil
+		}
+		p.next()
+		durTok, err := p.expect(tokenDuration)
+		if err != nil {
+			return Value{}, err
+		}
+		offset, err := parseDuration(durTok)
+		if err != nil {
+			return Value{}, err
+		}
+		if sign.kind == tokenMinus {
+			offset = -offset
+		}
+		return Value{Time: base.Add(offset)}, nil
+	}
+	return Value{}, errorAt(tok.pos, "invalid time %s, expected a date or now()", describe(tok))
+}
+
+// parseDuration reads durations such as 30m, 4h, 7d or 2w.
+func parseDuration(tok token) (time.Duration, error) {
+	i := strings.IndexFunc(tok.text, func(r rune) bool { return r < '0' || r > '9' })
+	n, err := strconv.Atoi(tok.text[:i])
+	if err != nil {
+		return 0, errorAt(tok.pos, "invalid duration %q", tok.text)
+	}
+	units := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	unit, ok := units[strings.ToLower(tok.text[i:])]
+	if !ok {
+		return 0, errorAt(tok.pos, "invalid duration unit in %q, expected m, h, d or w", tok.text)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func (p *parser) parseOrderBy() ([]OrderItem, error) {
+	var items []OrderItem
+	for {
+		tok, err := p.expect(tokenIdent)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(tok.text)
+		if !SortFields[name] {
+			return nil, errorAt(tok.pos, "cannot order by %q", tok.text)
+		}
+		item := OrderItem{Field: name}
+		if p.peekKeyword("DESC") {
+			p.next()
+			item.Desc = true
+		} else if p.peekKeyword("ASC") {
+			p.next()
+		}
+		items = append(items, item)
+
+		if p.peek().kind != tokenComma {
+			return items, nil
+		}
+		p.next()
+	}
+}
@@ -0,0 +1,219 @@
+package taskql
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// render writes an expression with explicit grouping so that tests can
+// compare tree shapes as strings.
+func render(e Expr) string {
+	switch e := e.(type) {
+	case nil:
+		return ""
+	case *And:
+		return "(" + render(e.Left) + " AND " + render(e.Right) + ")"
+	case *Or:
+		return "(" + render(e.Left) + " OR " + render(e.Right) + ")"
+	case *Not:
+		return "NOT(" + render(e.Expr) + ")"
+	case *Comparison:
+		values := make([]string, len(e.Values))
+		for i, value := range e.Values {
+			values[i] = renderValue(value)
+		}
+		switch e.Op {
+		case OpEmpty, OpNotEmpty:
+			return e.Field + " " + e.Op
+		case OpIn, OpNotIn:
+			return e.Field + " " + e.Op + " (" + strings.Join(values, ", ") + ")"
+		}
+		return e.Field + " " + e.Op + " " + values[0]
+	}
+	return "?"
+}
+
+func renderValue(v Value) string {
+	switch {
+	case v.Me:
+		return "me"
+	case v.Text != "":
+		return v.Text
+	case !v.Time.IsZero():
+		return v.Time.Format(time.RFC3339)
+	}
+	return strconv.FormatFloat(v.Number, 'g', -1, 64)
+}
+
+func renderOrder(items []OrderItem) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = item.Field
+		if item.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		where string
+		order string
+	}{
+		{
+			name:  "example from the request",
+			query: "status != DONE AND priority = HIGH AND due < now()+7d AND assignee in (me, 12) ORDER BY due",
+			where: "(((status != DONE AND priority = HIGH) AND due < 2026-01-17T12:00:00Z) AND assignee IN (me, 12))",
+			order: "due",
+		},
+		{
+			name:  "AND binds tighter than OR",
+			query: "status = TODO OR status = DONE AND priority = HIGH",
+			where: "(status = TODO OR (status = DONE AND priority = HIGH))",
+		},
+		{
+			name:  "parentheses override precedence",
+			query: "(status = TODO OR status = DONE) AND priority = HIGH",
+			where: "((status = TODO OR status = DONE) AND priority = HIGH)",
+		},
+		{
+			name:  "NOT binds tighter than AND",
+			query: "NOT status = DONE AND priority = LOW",
+			where: "(NOT(status = DONE) AND priority = LOW)",
+		},
+		{
+			name:  "NOT applies to a parenthesised group",
+			query: "NOT (status = DONE OR priority = LOW)",
+			where: "NOT((status = DONE OR priority = LOW))",
+		},
+		{
+			name:  "nested NOT",
+			query: "NOT NOT points > 3",
+			where: "NOT(NOT(points > 3))",
+		},
+		{
+			name:  "OR is left associative",
+			query: "points = 1 OR points = 2 OR points = 3",
+			where: "((points = 1 OR points = 2) OR points = 3)",
+		},
+		{
+			name:  "keywords and enum values are case-insensitive",
+			query: `status = todo or title ~ "fix bug"`,
+			where: "(status = TODO OR title ~ fix bug)",
+		},
+		{
+			name:  "IS NOT EMPTY and NOT IN",
+			query: `sprint is not empty and label not in (bug, "ui")`,
+			where: "(sprint IS NOT EMPTY AND label NOT IN (bug, ui))",
+		},
+		{
+			name:  "dates and today() offsets",
+			query: "due >= 2026-01-31 AND updated > today()-2w",
+			where: "(due >= 2026-01-31T00:00:00Z AND updated > 2025-12-27T00:00:00Z)",
+		},
+		{
+			name:  "RFC 3339 timestamp",
+			query: `created < "2026-01-05T08:30:00Z"`,
+			where: "created < 2026-01-05T08:30:00Z",
+		},
+		{
+			name:  "order only",
+			query: "ORDER BY priority DESC, due asc",
+			order: "priority DESC, due",
+		},
+		{
+			name: "empty query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := Parse(tt.query, testNow)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.query, err)
+			}
+			if got := render(query.Where); got != tt.where {
+				t.Errorf("Parse(%q) where = %s, want %s", tt.query, got, tt.where)
+			}
+			if got := renderOrder(query.OrderBy); got != tt.order {
+				t.Errorf("Parse(%q) order = %s, want %s", tt.query, got, tt.order)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		// Lexer errors.
+		{"status = TODO & priority = HIGH", 15, "unexpected character"},
+		{"status ! DONE", 8, "did you mean"},
+		{`title ~ "abc`, 9, "unterminated string"},
+		{`title ~ "é" &`, 13, "unexpected character"},
+
+		// Comparisons.
+		{"foo = 1", 1, "unknown field"},
+		{"= DONE", 1, "expected a field name"},
+		{"status DONE", 8, "expected an operator"},
+		{"status < DONE", 8, "cannot be used with status"},
+		{"status = DOING", 10, "invalid status"},
+		{"status NOT DONE", 12, "expected IN"},
+		{"sprint IS NULL", 11, "expected EMPTY"},
+		{"assignee in me", 13, "expected '('"},
+		{"assignee in (me, 12", 20, "expected ')'"},
+		{"assignee = bob", 12, "invalid value"},
+		{"points = high", 10, "invalid value"},
+
+		// Boolean structure.
+		{"(status = DONE", 15, "expected ')'"},
+		{"status = DONE AND", 18, "expected a field name"},
+		{"status = DONE priority = HIGH", 15, "expected AND, OR or ORDER BY"},
+
+		// Times.
+		{"due < 2026-13-01", 7, "invalid date"},
+		{"due < tomorrow()", 7, "unknown function"},
+		{"due < now", 10, "expected '('"},
+		{"due < now()+", 13, "expected duration"},
+		{"due < now()+7y", 13, "invalid duration unit"},
+
+		// ORDER BY.
+		{"ORDER due", 7, "expected BY"},
+		{"ORDER BY assignee", 10, "cannot order by"},
+		{"ORDER BY due,", 14, "expected identifier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query, testNow)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want a *SyntaxError", tt.query, err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error position = %d, want %d (%s)", tt.query, syntaxErr.Pos, tt.pos, syntaxErr.Msg)
+			}
+			if !strings.Contains(syntaxErr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error message = %q, want it to contain %q", tt.query, syntaxErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorMessage(t *testing.T) {
+	err := &SyntaxError{Pos: 4, Msg: "unknown field \"foo\""}
+	want := `syntax error at position 4: unknown field "foo"`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}